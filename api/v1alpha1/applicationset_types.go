@@ -32,6 +32,42 @@ import (
 type SecretRef struct {
 	SecretName string `json:"secretName"`
 	Key        string `json:"key"`
+	// Namespace of the secret. Defaults to the namespace of the ApplicationSet. Other namespaces must be allowed
+	// by the controller, and are supported by the SCM provider and pull request generators.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// TokenSource is a reference to a token available to the controller itself, as an alternative to a Secret.
+// Exactly one of File, Env, Vault and OIDCExchange must be set.
+type TokenSource struct {
+	// Path of a file containing the token, relative to the token directory of the controller.
+	File string `json:"file,omitempty"`
+	// Name of an environment variable of the controller containing the token.
+	Env string `json:"env,omitempty"`
+	// Vault reads the token from HashiCorp Vault, as configured on the controller.
+	Vault *VaultSecretRef `json:"vault,omitempty"`
+	// OIDCExchange exchanges the identity token of the controller for a token at a security token service, as
+	// configured on the controller.
+	OIDCExchange *OIDCTokenExchange `json:"oidcExchange,omitempty"`
+}
+
+// OIDCTokenExchange describes the token to request from a security token service.
+type OIDCTokenExchange struct {
+	// Scope of the token, eg the GitHub organization or owner/repo to access. It must be allowed by the controller.
+	// Required.
+	Scope string `json:"scope"`
+	// Identity is the name of the trust policy of the security token service to issue the token under. It must be
+	// allowed by the controller. Required.
+	Identity string `json:"identity"`
+}
+
+// VaultSecretRef is a reference to a key of a secret in HashiCorp Vault.
+type VaultSecretRef struct {
+	// Path of the secret, including the mount of its secrets engine, eg secret/data/scm for a secret of a KV
+	// version 2 engine mounted at secret. It must lie under the Vault path prefix of the controller. Required.
+	Path string `json:"path"`
+	// Key of the token within the secret. Required.
+	Key string `json:"key"`
 }
 
 // ApplicationSet is a set of Application resources
@@ -292,13 +328,22 @@ type GitFileGeneratorItem struct {
 // SCMProviderGenerator defines a generator that scrapes a SCMaaS API to find candidate repos.
 type SCMProviderGenerator struct {
 	// Which provider to use and config for it.
-	Github *SCMProviderGeneratorGithub `json:"github,omitempty"`
-	Gitlab *SCMProviderGeneratorGitlab `json:"gitlab,omitempty"`
+	Github          *SCMProviderGeneratorGithub          `json:"github,omitempty"`
+	Gitlab          *SCMProviderGeneratorGitlab          `json:"gitlab,omitempty"`
+	BitbucketServer *SCMProviderGeneratorBitbucketServer `json:"bitbucketServer,omitempty"`
+	Gitea           *SCMProviderGeneratorGitea           `json:"gitea,omitempty"`
+	AzureDevOps     *SCMProviderGeneratorAzureDevOps     `json:"azureDevOps,omitempty"`
+	AWSCodeCommit   *SCMProviderGeneratorAWSCodeCommit   `json:"awsCodeCommit,omitempty"`
+	Gerrit          *SCMProviderGeneratorGerrit          `json:"gerrit,omitempty"`
+	Gogs            *SCMProviderGeneratorGogs            `json:"gogs,omitempty"`
 	// Filters for which repos should be considered.
 	Filters []SCMProviderGeneratorFilter `json:"filters,omitempty"`
 	// Which protocol to use for the SCM URL. Default is provider-specific but ssh if possible. Not all providers
 	// necessarily support all protocols.
 	CloneProtocol string `json:"cloneProtocol,omitempty"`
+	// ContinueOnError generates the params of the repos that could be read when reading the branches or paths of
+	// others fails, rather than failing the generator. The failures are reported in the ApplicationSet status.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
 	// Standard parameters.
 	RequeueAfterSeconds *int64                 `json:"requeueAfterSeconds,omitempty"`
 	Template            ApplicationSetTemplate `json:"template,omitempty"`
@@ -312,6 +357,8 @@ type SCMProviderGeneratorGithub struct {
 	API string `json:"api,omitempty"`
 	// Authentication token reference.
 	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the authentication token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
 	// Scan all branches instead of just the default branch.
 	AllBranches bool `json:"allBranches,omitempty"`
 }
@@ -326,6 +373,107 @@ type SCMProviderGeneratorGitlab struct {
 	API string `json:"api,omitempty"`
 	// Authentication token reference.
 	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the authentication token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorBitbucketServer defines a connection info specific to Bitbucket Server (Data Center).
+type SCMProviderGeneratorBitbucketServer struct {
+	// Key of the Bitbucket Server project to scan. Required.
+	Project string `json:"project"`
+	// The Bitbucket Server URL to talk to. Required.
+	API string `json:"api"`
+	// Username to authenticate with using basic auth. If blank, and no token is set, anonymous requests are made.
+	Username string `json:"username,omitempty"`
+	// Reference to the password of the user. Required with username.
+	PasswordRef *SecretRef `json:"passwordRef,omitempty"`
+	// Reference to an HTTP access token, which is sent as a bearer token. Cannot be combined with username.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the access token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorGitea defines a connection info specific to Gitea.
+type SCMProviderGeneratorGitea struct {
+	// Gitea organization or user to scan. Required.
+	Owner string `json:"owner"`
+	// The Gitea URL to talk to. Required.
+	API string `json:"api"`
+	// Authentication token reference.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the authentication token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorAzureDevOps defines a connection info specific to Azure DevOps.
+type SCMProviderGeneratorAzureDevOps struct {
+	// Azure DevOps organization, or collection of Azure DevOps Server. Required.
+	Organization string `json:"organization"`
+	// Azure DevOps project to scan. Required.
+	Project string `json:"project"`
+	// The Azure DevOps URL to talk to, without the organization. If blank, use https://dev.azure.com.
+	API string `json:"api,omitempty"`
+	// Reference to a personal access token.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the personal access token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorAWSCodeCommit defines a connection info specific to AWS CodeCommit.
+type SCMProviderGeneratorAWSCodeCommit struct {
+	// TagFilters only scans the repositories whose tags match the filters. Filters of different keys must all
+	// match, while filters of the same key match if any of their values does, and a filter without a value makes
+	// any value of its key match.
+	TagFilters []SCMProviderGeneratorAWSTagFilter `json:"tagFilters,omitempty"`
+	// Role is the ARN of an IAM role to assume to scan the repositories, eg of another account. If blank, the
+	// credentials of the controller are used.
+	Role string `json:"role,omitempty"`
+	// Region of the repositories to scan. If blank, the region of the controller is used.
+	Region string `json:"region,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorAWSTagFilter is a tag a resource must have.
+type SCMProviderGeneratorAWSTagFilter struct {
+	// Key of the tag. Required.
+	Key string `json:"key"`
+	// Value of the tag. If blank, any value matches.
+	Value string `json:"value,omitempty"`
+}
+
+// SCMProviderGeneratorGerrit defines a connection info specific to Gerrit. Projects are treated as repositories.
+type SCMProviderGeneratorGerrit struct {
+	// The Gerrit URL to talk to. Required.
+	API string `json:"api"`
+	// Prefix of the names of the projects to scan, eg "platform/". If blank, all projects are scanned.
+	Prefix string `json:"prefix,omitempty"`
+	// Username to authenticate with. If blank, anonymous requests are made.
+	Username string `json:"username,omitempty"`
+	// Reference to the Gerrit HTTP password of the user.
+	PasswordRef *SecretRef `json:"passwordRef,omitempty"`
+	// Scan all branches instead of just the default branch.
+	AllBranches bool `json:"allBranches,omitempty"`
+}
+
+// SCMProviderGeneratorGogs defines a connection info specific to Gogs.
+type SCMProviderGeneratorGogs struct {
+	// Gogs organization to scan. Required.
+	Organization string `json:"organization"`
+	// The Gogs URL to talk to. Required.
+	API string `json:"api"`
+	// Authentication token reference.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the authentication token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
 	// Scan all branches instead of just the default branch.
 	AllBranches bool `json:"allBranches,omitempty"`
 }
@@ -348,9 +496,18 @@ type SCMProviderGeneratorFilter struct {
 type PullRequestGenerator struct {
 	// Which provider to use and config for it.
 	Github *PullRequestGeneratorGithub `json:"github,omitempty"`
+	// Gerrit lists the open changes of a Gerrit project as pull requests.
+	Gerrit *PullRequestGeneratorGerrit `json:"gerrit,omitempty"`
+	Plugin *PullRequestGeneratorPlugin `json:"plugin,omitempty"`
+	// Filters for which pull requests should be considered.
+	Filters []PullRequestGeneratorFilter `json:"filters,omitempty"`
 	// Standard parameters.
 	RequeueAfterSeconds *int64                 `json:"requeueAfterSeconds,omitempty"`
 	Template            ApplicationSetTemplate `json:"template,omitempty"`
+
+	// Values contains key/value pairs which are passed as parameters to the template, after replacing the
+	// placeholders of the parameters of each pull request, eg {{branch_slug}}.
+	Values map[string]string `json:"values,omitempty"`
 }
 
 // PullRequestGenerator defines a connection info specific to GitHub.
@@ -363,8 +520,95 @@ type PullRequestGeneratorGithub struct {
 	API string `json:"api,omitempty"`
 	// Authentication token reference.
 	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the authentication token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// App authenticates as a GitHub App installation instead of with a token.
+	App *PullRequestGeneratorGithubApp `json:"app,omitempty"`
 	// Labels is used to filter the PRs that you want to target
 	Labels []string `json:"labels,omitempty"`
+	// ExcludeLabels is used to filter out PRs carrying any of these labels
+	ExcludeLabels []string `json:"excludeLabels,omitempty"`
+	// Proxy is the URL of an HTTP(S) proxy to send API requests through. If blank, the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy string `json:"proxy,omitempty"`
+	// SkipDraft excludes draft PRs.
+	SkipDraft bool `json:"skipDraft,omitempty"`
+	// A regex which must match the name of the branch the PR targets.
+	BaseBranchMatch *string `json:"baseBranchMatch,omitempty"`
+	// A regex which must match the login of the PR author.
+	AuthorMatch *string `json:"authorMatch,omitempty"`
+	// AuthorTeams is a list of slugs of teams of the owner organization. If set, the PR author must be a member
+	// of at least one of them.
+	AuthorTeams []string `json:"authorTeams,omitempty"`
+	// Milestone is the title of the milestone the PR must be assigned to.
+	Milestone string `json:"milestone,omitempty"`
+	// SkipUnmergeable excludes PRs which have merge conflicts or are blocked from merging.
+	SkipUnmergeable bool `json:"skipUnmergeable,omitempty"`
+	// Checks, if set, only targets PRs whose head commit passed CI.
+	Checks *PullRequestGeneratorGithubChecks `json:"checks,omitempty"`
+}
+
+// PullRequestGeneratorGithubApp defines the GitHub App installation to authenticate as.
+type PullRequestGeneratorGithubApp struct {
+	// ID of the GitHub App. Required.
+	AppID int64 `json:"appID"`
+	// ID of the installation of the GitHub App in the account owning the repo. Required.
+	InstallationID int64 `json:"installationID"`
+	// Reference to the PEM-encoded private key of the GitHub App. Required.
+	PrivateKeyRef SecretRef `json:"privateKeyRef"`
+}
+
+// PullRequestGeneratorGithubChecks defines the check runs and commit statuses a pull request's head commit must pass.
+type PullRequestGeneratorGithubChecks struct {
+	// Required is a list of regexes matched against check run names and commit status contexts. Each must match
+	// at least one check, and all matching checks must be successful. If empty, every check must be successful.
+	Required []string `json:"required,omitempty"`
+	// FindLatestSuccessful targets the newest earlier commit of the PR that passed the required checks if the head
+	// commit didn't, instead of skipping the PR.
+	FindLatestSuccessful bool `json:"findLatestSuccessful,omitempty"`
+}
+
+// PullRequestGeneratorGerrit defines a connection info specific to Gerrit. Open changes are treated as pull requests.
+type PullRequestGeneratorGerrit struct {
+	// The Gerrit URL to talk to. Required.
+	API string `json:"api"`
+	// Gerrit project to scan. Required.
+	Project string `json:"project"`
+	// Username to authenticate with. If blank, anonymous requests are made.
+	Username string `json:"username,omitempty"`
+	// Reference to the Gerrit HTTP password of the user.
+	PasswordRef *SecretRef `json:"passwordRef,omitempty"`
+	// Proxy is the URL of an HTTP(S) proxy to send API requests through. If blank, the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// PullRequestGeneratorPlugin defines an external HTTP service to get pull requests from.
+type PullRequestGeneratorPlugin struct {
+	// Name of a ConfigMap in the ApplicationSet's namespace whose baseUrl key holds the URL of the plugin. Required.
+	ConfigMapRef string `json:"configMapRef"`
+	// Reference to a Secret containing the token sent to the plugin as a bearer token.
+	TokenRef *SecretRef `json:"tokenRef,omitempty"`
+	// TokenFrom reads the token from a source of the controller instead, see TokenSource.
+	TokenFrom *TokenSource `json:"tokenFrom,omitempty"`
+	// Input is sent to the plugin with every request.
+	Input map[string]string `json:"input,omitempty"`
+}
+
+// PullRequestGeneratorFilter is a single pull request filter.
+// If multiple filter types are set on a single struct, they will be AND'd together. All filters must
+// pass for a pull request to be included.
+type PullRequestGeneratorFilter struct {
+	// A regex which must match the branch name.
+	BranchMatch *string `json:"branchMatch,omitempty"`
+	// A regex which must match the name of the branch the pull request targets.
+	TargetBranchMatch *string `json:"targetBranchMatch,omitempty"`
+	// An array of labels, all of which the pull request must carry.
+	Labels []string `json:"labels,omitempty"`
+	// An array of usernames, one of which must be the pull request author.
+	Authors []string `json:"authors,omitempty"`
+	// The maximum time since the pull request was last updated.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // ApplicationSetStatus defines the observed state of ApplicationSet
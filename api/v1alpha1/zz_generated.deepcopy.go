@@ -20,6 +20,7 @@ package v1alpha1
 
 import (
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -651,6 +652,21 @@ func (in *NestedMergeGenerator) DeepCopy() *NestedMergeGenerator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCTokenExchange) DeepCopyInto(out *OIDCTokenExchange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCTokenExchange.
+func (in *OIDCTokenExchange) DeepCopy() *OIDCTokenExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullRequestGenerator) DeepCopyInto(out *PullRequestGenerator) {
 	*out = *in
@@ -659,12 +675,36 @@ func (in *PullRequestGenerator) DeepCopyInto(out *PullRequestGenerator) {
 		*out = new(PullRequestGeneratorGithub)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Gerrit != nil {
+		in, out := &in.Gerrit, &out.Gerrit
+		*out = new(PullRequestGeneratorGerrit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugin != nil {
+		in, out := &in.Plugin, &out.Plugin
+		*out = new(PullRequestGeneratorPlugin)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]PullRequestGeneratorFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.RequeueAfterSeconds != nil {
 		in, out := &in.RequeueAfterSeconds, &out.RequeueAfterSeconds
 		*out = new(int64)
 		**out = **in
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGenerator.
@@ -677,6 +717,66 @@ func (in *PullRequestGenerator) DeepCopy() *PullRequestGenerator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestGeneratorFilter) DeepCopyInto(out *PullRequestGeneratorFilter) {
+	*out = *in
+	if in.BranchMatch != nil {
+		in, out := &in.BranchMatch, &out.BranchMatch
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetBranchMatch != nil {
+		in, out := &in.TargetBranchMatch, &out.TargetBranchMatch
+		*out = new(string)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Authors != nil {
+		in, out := &in.Authors, &out.Authors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorFilter.
+func (in *PullRequestGeneratorFilter) DeepCopy() *PullRequestGeneratorFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestGeneratorFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestGeneratorGerrit) DeepCopyInto(out *PullRequestGeneratorGerrit) {
+	*out = *in
+	if in.PasswordRef != nil {
+		in, out := &in.PasswordRef, &out.PasswordRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorGerrit.
+func (in *PullRequestGeneratorGerrit) DeepCopy() *PullRequestGeneratorGerrit {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestGeneratorGerrit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullRequestGeneratorGithub) DeepCopyInto(out *PullRequestGeneratorGithub) {
 	*out = *in
@@ -685,11 +785,46 @@ func (in *PullRequestGeneratorGithub) DeepCopyInto(out *PullRequestGeneratorGith
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.App != nil {
+		in, out := &in.App, &out.App
+		*out = new(PullRequestGeneratorGithubApp)
+		**out = **in
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExcludeLabels != nil {
+		in, out := &in.ExcludeLabels, &out.ExcludeLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BaseBranchMatch != nil {
+		in, out := &in.BaseBranchMatch, &out.BaseBranchMatch
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthorMatch != nil {
+		in, out := &in.AuthorMatch, &out.AuthorMatch
+		*out = new(string)
+		**out = **in
+	}
+	if in.AuthorTeams != nil {
+		in, out := &in.AuthorTeams, &out.AuthorTeams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = new(PullRequestGeneratorGithubChecks)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorGithub.
@@ -702,6 +837,74 @@ func (in *PullRequestGeneratorGithub) DeepCopy() *PullRequestGeneratorGithub {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestGeneratorGithubApp) DeepCopyInto(out *PullRequestGeneratorGithubApp) {
+	*out = *in
+	out.PrivateKeyRef = in.PrivateKeyRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorGithubApp.
+func (in *PullRequestGeneratorGithubApp) DeepCopy() *PullRequestGeneratorGithubApp {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestGeneratorGithubApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestGeneratorGithubChecks) DeepCopyInto(out *PullRequestGeneratorGithubChecks) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorGithubChecks.
+func (in *PullRequestGeneratorGithubChecks) DeepCopy() *PullRequestGeneratorGithubChecks {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestGeneratorGithubChecks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestGeneratorPlugin) DeepCopyInto(out *PullRequestGeneratorPlugin) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Input != nil {
+		in, out := &in.Input, &out.Input
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestGeneratorPlugin.
+func (in *PullRequestGeneratorPlugin) DeepCopy() *PullRequestGeneratorPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestGeneratorPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCMProviderGenerator) DeepCopyInto(out *SCMProviderGenerator) {
 	*out = *in
@@ -715,6 +918,36 @@ func (in *SCMProviderGenerator) DeepCopyInto(out *SCMProviderGenerator) {
 		*out = new(SCMProviderGeneratorGitlab)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BitbucketServer != nil {
+		in, out := &in.BitbucketServer, &out.BitbucketServer
+		*out = new(SCMProviderGeneratorBitbucketServer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gitea != nil {
+		in, out := &in.Gitea, &out.Gitea
+		*out = new(SCMProviderGeneratorGitea)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AzureDevOps != nil {
+		in, out := &in.AzureDevOps, &out.AzureDevOps
+		*out = new(SCMProviderGeneratorAzureDevOps)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AWSCodeCommit != nil {
+		in, out := &in.AWSCodeCommit, &out.AWSCodeCommit
+		*out = new(SCMProviderGeneratorAWSCodeCommit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gerrit != nil {
+		in, out := &in.Gerrit, &out.Gerrit
+		*out = new(SCMProviderGeneratorGerrit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gogs != nil {
+		in, out := &in.Gogs, &out.Gogs
+		*out = new(SCMProviderGeneratorGogs)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Filters != nil {
 		in, out := &in.Filters, &out.Filters
 		*out = make([]SCMProviderGeneratorFilter, len(*in))
@@ -740,6 +973,96 @@ func (in *SCMProviderGenerator) DeepCopy() *SCMProviderGenerator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorAWSCodeCommit) DeepCopyInto(out *SCMProviderGeneratorAWSCodeCommit) {
+	*out = *in
+	if in.TagFilters != nil {
+		in, out := &in.TagFilters, &out.TagFilters
+		*out = make([]SCMProviderGeneratorAWSTagFilter, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorAWSCodeCommit.
+func (in *SCMProviderGeneratorAWSCodeCommit) DeepCopy() *SCMProviderGeneratorAWSCodeCommit {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorAWSCodeCommit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorAWSTagFilter) DeepCopyInto(out *SCMProviderGeneratorAWSTagFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorAWSTagFilter.
+func (in *SCMProviderGeneratorAWSTagFilter) DeepCopy() *SCMProviderGeneratorAWSTagFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorAWSTagFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorAzureDevOps) DeepCopyInto(out *SCMProviderGeneratorAzureDevOps) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorAzureDevOps.
+func (in *SCMProviderGeneratorAzureDevOps) DeepCopy() *SCMProviderGeneratorAzureDevOps {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorAzureDevOps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorBitbucketServer) DeepCopyInto(out *SCMProviderGeneratorBitbucketServer) {
+	*out = *in
+	if in.PasswordRef != nil {
+		in, out := &in.PasswordRef, &out.PasswordRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorBitbucketServer.
+func (in *SCMProviderGeneratorBitbucketServer) DeepCopy() *SCMProviderGeneratorBitbucketServer {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorBitbucketServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCMProviderGeneratorFilter) DeepCopyInto(out *SCMProviderGeneratorFilter) {
 	*out = *in
@@ -775,6 +1098,51 @@ func (in *SCMProviderGeneratorFilter) DeepCopy() *SCMProviderGeneratorFilter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorGerrit) DeepCopyInto(out *SCMProviderGeneratorGerrit) {
+	*out = *in
+	if in.PasswordRef != nil {
+		in, out := &in.PasswordRef, &out.PasswordRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorGerrit.
+func (in *SCMProviderGeneratorGerrit) DeepCopy() *SCMProviderGeneratorGerrit {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorGerrit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorGitea) DeepCopyInto(out *SCMProviderGeneratorGitea) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorGitea.
+func (in *SCMProviderGeneratorGitea) DeepCopy() *SCMProviderGeneratorGitea {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorGitea)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCMProviderGeneratorGithub) DeepCopyInto(out *SCMProviderGeneratorGithub) {
 	*out = *in
@@ -783,6 +1151,11 @@ func (in *SCMProviderGeneratorGithub) DeepCopyInto(out *SCMProviderGeneratorGith
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorGithub.
@@ -803,6 +1176,11 @@ func (in *SCMProviderGeneratorGitlab) DeepCopyInto(out *SCMProviderGeneratorGitl
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorGitlab.
@@ -815,6 +1193,31 @@ func (in *SCMProviderGeneratorGitlab) DeepCopy() *SCMProviderGeneratorGitlab {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SCMProviderGeneratorGogs) DeepCopyInto(out *SCMProviderGeneratorGogs) {
+	*out = *in
+	if in.TokenRef != nil {
+		in, out := &in.TokenRef, &out.TokenRef
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.TokenFrom != nil {
+		in, out := &in.TokenFrom, &out.TokenFrom
+		*out = new(TokenSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SCMProviderGeneratorGogs.
+func (in *SCMProviderGeneratorGogs) DeepCopy() *SCMProviderGeneratorGogs {
+	if in == nil {
+		return nil
+	}
+	out := new(SCMProviderGeneratorGogs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -829,3 +1232,43 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenSource) DeepCopyInto(out *TokenSource) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretRef)
+		**out = **in
+	}
+	if in.OIDCExchange != nil {
+		in, out := &in.OIDCExchange, &out.OIDCExchange
+		*out = new(OIDCTokenExchange)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenSource.
+func (in *TokenSource) DeepCopy() *TokenSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretRef) DeepCopyInto(out *VaultSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretRef.
+func (in *VaultSecretRef) DeepCopy() *VaultSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
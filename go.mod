@@ -3,14 +3,20 @@ module github.com/argoproj/applicationset
 go 1.16
 
 require (
+	code.gitea.io/sdk/gitea v0.15.1
 	github.com/argoproj/argo-cd/v2 v2.2.0
 	github.com/argoproj/gitops-engine v0.5.1
 	github.com/argoproj/pkg v0.11.1-0.20211203175135-36c59d8fafe0
+	github.com/aws/aws-sdk-go v1.38.49
+	github.com/bradleyfalzon/ghinstallation/v2 v2.0.2
 	github.com/go-logr/logr v0.4.0
 	github.com/google/go-github/v35 v35.0.0
+	github.com/google/uuid v1.1.2
 	github.com/imdario/mergo v0.3.12
 	github.com/jeremywohl/flatten v1.0.1
+	github.com/microsoft/azure-devops-go-api/azuredevops v1.0.0-b5
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/valyala/fasttemplate v1.2.1
@@ -31,6 +31,7 @@ import (
 	"github.com/argoproj/applicationset/pkg/controllers"
 	"github.com/argoproj/applicationset/pkg/generators"
 	"github.com/argoproj/applicationset/pkg/services"
+	"github.com/argoproj/applicationset/pkg/services/credentials"
 	"github.com/argoproj/applicationset/pkg/utils"
 
 	"github.com/argoproj/applicationset/common"
@@ -81,6 +82,14 @@ func main() {
 	var dryRun bool
 	var logFormat string
 	var logLevel string
+	var tokenDir string
+	var allowedSecretNamespaces string
+	var vaultConfig credentials.VaultConfig
+	var vaultPathPrefix string
+	var tokenExchangeURL string
+	var tokenExchangeScopes string
+	var tokenExchangeIdentities string
+	var oidcTokenPath string
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeBindAddr, "probe-addr", ":8081", "The address the probe endpoint binds to.")
@@ -95,6 +104,16 @@ func main() {
 	flag.StringVar(&logLevel, "loglevel", "info", "Set the logging level. One of: debug|info|warn|error")
 	flag.BoolVar(&dryRun, "dry-run", false, "Enable dry run mode")
 	flag.StringVar(&logFormat, "logformat", "text", "Set the logging format. One of: text|json")
+	flag.StringVar(&allowedSecretNamespaces, "allowed-secret-namespaces", "", "Comma-separated list of namespaces, besides the ApplicationSet's, that secrets of the SCM Provider and Pull Request generators may be read from")
+	flag.StringVar(&vaultConfig.Address, "vault-addr", "", "Address of the HashiCorp Vault server SCM provider tokens may be read from with tokenFrom.vault. Reading tokens from Vault is disabled if empty")
+	flag.StringVar(&vaultConfig.Role, "vault-role", "", "Vault role to log in as with the Kubernetes auth method")
+	flag.StringVar(&vaultPathPrefix, "vault-path-prefix", "", "Path in Vault, including the mount of the secrets engine, which the secrets read with tokenFrom.vault must lie under. Required with --vault-addr")
+	flag.StringVar(&vaultConfig.AuthMount, "vault-auth-mount", credentials.DefaultVaultAuthMount, "Path the Kubernetes auth method is enabled at in Vault")
+	flag.StringVar(&tokenExchangeURL, "token-exchange-url", "", "URL of the exchange endpoint of a security token service SCM provider tokens may be requested from with tokenFrom.oidcExchange. Token exchange is disabled if empty")
+	flag.StringVar(&tokenExchangeScopes, "token-exchange-scopes", "", "Comma-separated list of the scopes tokens may be requested for with tokenFrom.oidcExchange, as patterns like myorg/* where * matches any characters but /. Required with --token-exchange-url")
+	flag.StringVar(&tokenExchangeIdentities, "token-exchange-identities", "", "Comma-separated list of the identities tokens may be requested under with tokenFrom.oidcExchange. Required with --token-exchange-url")
+	flag.StringVar(&oidcTokenPath, "oidc-token-path", credentials.DefaultServiceAccountTokenPath, "File containing the identity token exchanged with the security token service, typically a projected service account token")
+	flag.StringVar(&tokenDir, "token-dir", "", "Directory SCM provider tokens may be read from with tokenFrom.file. Reading tokens from files is disabled if empty")
 	flag.Parse()
 
 	json := strings.ToLower(logFormat) == JsonFormat
@@ -154,6 +173,12 @@ func main() {
 
 	argoCDDB := db.NewDB(namespace, argoSettingsMgr, k8s)
 
+	// index ApplicationSets by the repositories they reference, to match them against webhook payloads
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &argoprojiov1alpha1.ApplicationSet{}, utils.WebhookRepoIndex, utils.IndexApplicationSetRepos); err != nil {
+		setupLog.Error(err, "unable to index applicationsets by repository")
+		os.Exit(1)
+	}
+
 	// start a webhook server that listens to incoming webhook payloads
 	webhookHandler, err := utils.NewWebhookHandler(namespace, argoSettingsMgr, mgr.GetClient())
 	if err != nil {
@@ -164,13 +189,42 @@ func main() {
 		startWebhookServer(webhookHandler, webhookAddr)
 	}
 
+	tokenReader := &utils.TokenReader{Dir: tokenDir}
+	if vaultConfig.Address != "" {
+		if strings.Trim(vaultPathPrefix, "/") == "" {
+			setupLog.Error(fmt.Errorf("--vault-path-prefix is required with --vault-addr"), "unable to create vault client")
+			os.Exit(1)
+		}
+		tokenReader.VaultPathPrefix = vaultPathPrefix
+		vaultClient, err := credentials.NewVaultClient(vaultConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create vault client")
+			os.Exit(1)
+		}
+		tokenReader.Vault = vaultClient
+	}
+	if tokenExchangeURL != "" {
+		tokenReader.OIDCExchangeScopes = splitList(tokenExchangeScopes)
+		tokenReader.OIDCExchangeIdentities = splitList(tokenExchangeIdentities)
+		if len(tokenReader.OIDCExchangeScopes) == 0 || len(tokenReader.OIDCExchangeIdentities) == 0 {
+			setupLog.Error(fmt.Errorf("--token-exchange-scopes and --token-exchange-identities are required with --token-exchange-url"), "unable to create token exchanger")
+			os.Exit(1)
+		}
+		exchanger, err := credentials.NewOIDCTokenExchanger(tokenExchangeURL, oidcTokenPath)
+		if err != nil {
+			setupLog.Error(err, "unable to create token exchanger")
+			os.Exit(1)
+		}
+		tokenReader.OIDCExchange = exchanger
+	}
+
 	terminalGenerators := map[string]generators.Generator{
 		"List":                    generators.NewListGenerator(),
 		"Clusters":                generators.NewClusterGenerator(mgr.GetClient(), context.Background(), k8s, namespace),
 		"Git":                     generators.NewGitGenerator(services.NewArgoCDService(argoCDDB, argocdRepoServer)),
-		"SCMProvider":             generators.NewSCMProviderGenerator(mgr.GetClient()),
+		"SCMProvider":             generators.NewSCMProviderGenerator(mgr.GetClient(), tokenReader, mgr.GetAPIReader(), splitList(allowedSecretNamespaces)),
 		"ClusterDecisionResource": generators.NewDuckTypeGenerator(context.Background(), dynClient, k8s, namespace),
-		"PullRequest":             generators.NewPullRequestGenerator(mgr.GetClient()),
+		"PullRequest":             generators.NewPullRequestGenerator(mgr.GetClient(), tokenReader, mgr.GetAPIReader(), splitList(allowedSecretNamespaces)),
 	}
 
 	nestedGenerators := map[string]generators.Generator{
@@ -236,6 +290,17 @@ func setLoggingLevel(debug bool, logLevel string) {
 	}
 }
 
+// splitList parses a comma-separated flag value, ignoring empty entries.
+func splitList(list string) []string {
+	var result []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
 func startWebhookServer(webhookHandler *utils.WebhookHandler, webhookAddr string) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/webhook", webhookHandler.Handler)
@@ -16,11 +16,13 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/argoproj/applicationset/common"
 	"github.com/argoproj/applicationset/pkg/generators"
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
 	"github.com/argoproj/applicationset/pkg/utils"
 	argov1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v2/util/db"
@@ -94,7 +96,11 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	utils.CheckInvalidGenerators(&applicationSetInfo)
 	// desiredApplications is the main list of all expected Applications from all generators in this appset.
 	desiredApplications, applicationSetReason, err := r.generateApplications(applicationSetInfo)
-	if err != nil {
+	// If some generators could only generate part of their params, the Applications that were generated are still
+	// reconciled, but none are deleted, as the missing ones may only have failed to generate.
+	var partialErr *generators.PartialParamsError
+	partial := errors.As(err, &partialErr)
+	if err != nil && !partial {
 		_ = r.setApplicationSetStatusCondition(ctx,
 			&applicationSetInfo,
 			argoprojiov1alpha1.ApplicationSetCondition{
@@ -104,9 +110,28 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
 			}, parametersGenerated,
 		)
+		// An exhausted SCM provider rate limit is expected to clear on its own, so rather than backing off
+		// exponentially, try again once it has reset.
+		var rateLimitErr *ratelimit.Error
+		if errors.As(err, &rateLimitErr) {
+			log.WithField("applicationset", req.NamespacedName).Warnf("requeuing after SCM provider rate limit: %v", err)
+			return ctrl.Result{RequeueAfter: rateLimitErr.RetryAfter}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
+	if partial {
+		_ = r.setApplicationSetStatusCondition(ctx,
+			&applicationSetInfo,
+			argoprojiov1alpha1.ApplicationSetCondition{
+				Type:    argoprojiov1alpha1.ApplicationSetConditionErrorOccurred,
+				Message: err.Error(),
+				Reason:  string(applicationSetReason),
+				Status:  argoprojiov1alpha1.ApplicationSetConditionStatusTrue,
+			}, parametersGenerated,
+		)
+	}
+
 	parametersGenerated = true
 
 	validateErrors, err := r.validateGeneratedApplications(ctx, desiredApplications, applicationSetInfo, req.Namespace)
@@ -190,7 +215,7 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	if r.Policy.Delete() {
+	if r.Policy.Delete() && !partial {
 		err = r.deleteInCluster(ctx, applicationSetInfo, desiredApplications)
 		if err != nil {
 			_ = r.setApplicationSetStatusCondition(ctx,
@@ -227,7 +252,7 @@ func (r *ApplicationSetReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	requeueAfter := r.getMinRequeueAfter(&applicationSetInfo)
 	log.WithField("requeueAfter", requeueAfter).Info("end reconcile")
 
-	if len(validateErrors) == 0 {
+	if len(validateErrors) == 0 && !partial {
 		if err := r.setApplicationSetStatusCondition(ctx,
 			&applicationSetInfo,
 			argoprojiov1alpha1.ApplicationSetCondition{
@@ -427,17 +452,27 @@ func (r *ApplicationSetReconciler) generateApplications(applicationSetInfo argop
 
 	var firstError error
 	var applicationSetReason argoprojiov1alpha1.ApplicationSetReasonType
+	// partialError is the first error of a generator that could generate part of its params.
+	var partialError error
 
 	for _, requestedGenerator := range applicationSetInfo.Spec.Generators {
 		t, err := generators.Transform(requestedGenerator, r.Generators, applicationSetInfo.Spec.Template, &applicationSetInfo)
 		if err != nil {
+			var partialErr *generators.PartialParamsError
+			if !errors.As(err, &partialErr) {
+				log.WithError(err).WithField("generator", requestedGenerator).
+					Error("error generating application from params")
+				if firstError == nil {
+					firstError = err
+					applicationSetReason = argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError
+				}
+				continue
+			}
 			log.WithError(err).WithField("generator", requestedGenerator).
-				Error("error generating application from params")
-			if firstError == nil {
-				firstError = err
-				applicationSetReason = argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError
+				Warn("error generating some applications from params")
+			if partialError == nil {
+				partialError = err
 			}
-			continue
 		}
 
 		for _, a := range t {
@@ -463,6 +498,9 @@ func (r *ApplicationSetReconciler) generateApplications(applicationSetInfo argop
 		log.WithField("generator", requestedGenerator).Debugf("apps from generator: %+v", res)
 	}
 
+	if firstError == nil && partialError != nil {
+		return res, argoprojiov1alpha1.ApplicationSetReasonApplicationParamsGenerationError, partialError
+	}
 	return res, applicationSetReason, firstError
 }
 
@@ -107,6 +107,20 @@ func TestExtractApplications(t *testing.T) {
 			expectErr:           true,
 			expectedReason:      v1alpha1.ApplicationSetReasonApplicationParamsGenerationError,
 		},
+		{
+			name:   "Keeps the params of a generator that continued on error",
+			params: []map[string]string{{"name": "app1"}},
+			template: argoprojiov1alpha1.ApplicationSetTemplate{
+				ApplicationSetTemplateMeta: argoprojiov1alpha1.ApplicationSetTemplateMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: argov1alpha1.ApplicationSpec{},
+			},
+			generateParamsError: &generators.PartialParamsError{Err: errors.New("error")},
+			expectErr:           true,
+			expectedReason:      v1alpha1.ApplicationSetReasonApplicationParamsGenerationError,
+		},
 		{
 			name:   "Handles error from the render",
 			params: []map[string]string{{"name": "app1"}, {"name": "app2"}},
@@ -156,7 +170,9 @@ func TestExtractApplications(t *testing.T) {
 
 			var expectedApps []argov1alpha1.Application
 
-			if cc.generateParamsError == nil {
+			var partialErr *generators.PartialParamsError
+			paramsGenerated := cc.generateParamsError == nil || errors.As(cc.generateParamsError, &partialErr)
+			if paramsGenerated {
 				for _, p := range cc.params {
 
 					if cc.rendererError != nil {
@@ -201,7 +217,7 @@ func TestExtractApplications(t *testing.T) {
 			assert.Equal(t, cc.expectedReason, reason)
 			generatorMock.AssertNumberOfCalls(t, "GenerateParams", 1)
 
-			if cc.generateParamsError == nil {
+			if paramsGenerated {
 				rendererMock.AssertNumberOfCalls(t, "RenderTemplateParams", len(cc.params))
 			}
 
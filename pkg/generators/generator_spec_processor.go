@@ -1,6 +1,7 @@
 package generators
 
 import (
+	"errors"
 	"reflect"
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
@@ -51,12 +52,21 @@ func Transform(requestedGenerator argoprojiov1alpha1.ApplicationSetGenerator, al
 
 		params, err := g.GenerateParams(&requestedGenerator, appSet)
 		if err != nil {
+			var partialErr *PartialParamsError
+			if !errors.As(err, &partialErr) {
+				log.WithError(err).WithField("generator", g).
+					Error("error generating params")
+				if firstError == nil || errors.As(firstError, &partialErr) {
+					firstError = err
+				}
+				continue
+			}
+			// Keep the params that could be generated, and report the error unless there is a worse one.
 			log.WithError(err).WithField("generator", g).
-				Error("error generating params")
+				Warn("error generating some params")
 			if firstError == nil {
 				firstError = err
 			}
-			continue
 		}
 
 		res = append(res, TransformResult{
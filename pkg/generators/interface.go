@@ -26,6 +26,20 @@ type Generator interface {
 var EmptyAppSetGeneratorError = errors.New("ApplicationSet is empty")
 var NoRequeueAfter time.Duration
 
+// PartialParamsError is returned by GenerateParams along with the params that could be generated, when a generator
+// configured to continue on errors failed to generate the params of some of its inputs.
+type PartialParamsError struct {
+	Err error
+}
+
+func (e *PartialParamsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialParamsError) Unwrap() error {
+	return e.Err
+}
+
 // DefaultRequeueAfterSeconds is used when GetRequeueAfter is not specified, it is the default time to wait before the next reconcile loop
 const (
 	DefaultRequeueAfterSeconds = 3 * time.Minute
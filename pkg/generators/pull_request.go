@@ -3,30 +3,57 @@ package generators
 import (
 	"context"
 	"fmt"
+	"io"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/valyala/fasttemplate"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
 	pullrequest "github.com/argoproj/applicationset/pkg/services/pull_request"
+	"github.com/argoproj/applicationset/pkg/utils"
 )
 
 var _ Generator = (*PullRequestGenerator)(nil)
 
 const (
 	DefaultPullRequestRequeueAfterSeconds = 30 * time.Minute
+	// pluginBaseURLKey is the key of the plugin ConfigMap holding the URL of the plugin.
+	pluginBaseURLKey = "baseUrl"
+	// shortSHALength is the length of the head_short_sha param.
+	shortSHALength = 8
+	// branchSlugMaxLength is the maximum length of the branch_slug param. It leaves 13 characters of a DNS label for
+	// a prefix or suffix added by the template.
+	branchSlugMaxLength = 50
 )
 
+// invalidSlugChars matches the characters not allowed in a DNS label.
+var invalidSlugChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
 type PullRequestGenerator struct {
-	client                    client.Client
+	client client.Client
+	// tokenReader reads the tokens referenced by tokenFrom.
+	tokenReader *utils.TokenReader
+	// secretReader reads secrets from allowedSecretNamespaces, which the cache of client doesn't cover.
+	secretReader client.Reader
+	// allowedSecretNamespaces are the namespaces other than the ApplicationSet's that secrets may be read from.
+	allowedSecretNamespaces []string
+	// githubAppTransports keeps the installation tokens of GitHub Apps across reconciles.
+	githubAppTransports       *pullrequest.GithubAppTransports
 	selectServiceProviderFunc func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error)
 }
 
-func NewPullRequestGenerator(client client.Client) Generator {
+func NewPullRequestGenerator(client client.Client, tokenReader *utils.TokenReader, secretReader client.Reader, allowedSecretNamespaces []string) Generator {
 	g := &PullRequestGenerator{
-		client: client,
+		client:                  client,
+		tokenReader:             tokenReader,
+		secretReader:            secretReader,
+		allowedSecretNamespaces: allowedSecretNamespaces,
+		githubAppTransports:     pullrequest.NewGithubAppTransports(),
 	}
 	g.selectServiceProviderFunc = g.selectServiceProvider
 	return g
@@ -61,54 +88,164 @@ func (g *PullRequestGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha
 		return nil, fmt.Errorf("failed to select pull request service provider: %v", err)
 	}
 
-	pulls, err := svc.List(ctx)
+	pulls, err := pullrequest.ListPullRequests(ctx, svc, appSetGenerator.PullRequest.Filters)
 	if err != nil {
-		return nil, fmt.Errorf("error listing repos: %v", err)
+		return nil, fmt.Errorf("error listing repos: %w", err)
 	}
 	params := make([]map[string]string, 0, len(pulls))
 	for _, pull := range pulls {
-		params = append(params, map[string]string{
-			"number":   strconv.Itoa(pull.Number),
-			"branch":   pull.Branch,
-			"head_sha": pull.HeadSHA,
-		})
+		param := map[string]string{
+			"number":         strconv.Itoa(pull.Number),
+			"branch":         pull.Branch,
+			"branch_slug":    slugify(pull.Branch, branchSlugMaxLength),
+			"head_sha":       pull.HeadSHA,
+			"head_short_sha": shortSHA(pull.HeadSHA),
+			"title":          pull.Title,
+			"author":         pull.Author,
+			"url":            pull.URL,
+			"target_branch":  pull.TargetBranch,
+			"created_at":     formatPullRequestTime(pull.CreatedAt),
+			"updated_at":     formatPullRequestTime(pull.UpdatedAt),
+			"labels":         strings.Join(pull.Labels, ","),
+		}
+		// Flag each label individually, so templates can test for a specific one.
+		for _, label := range pull.Labels {
+			param["labels."+label] = "true"
+		}
+		// Values are interpolated with the params above, so they can't reference each other.
+		values := make(map[string]string, len(appSetGenerator.PullRequest.Values))
+		for key, value := range appSetGenerator.PullRequest.Values {
+			values["values."+key] = interpolateParams(value, param)
+		}
+		for key, value := range values {
+			param[key] = value
+		}
+		params = append(params, param)
 	}
 	return params, nil
 }
 
+// interpolateParams replaces the {{param}} placeholders in value with the values of params. Placeholders of unknown
+// params are left as is, so that they can still be resolved when rendering the template.
+func interpolateParams(value string, params map[string]string) string {
+	return fasttemplate.ExecuteFuncString(value, "{{", "}}", func(w io.Writer, tag string) (int, error) {
+		replacement, ok := params[strings.TrimSpace(tag)]
+		if !ok {
+			return w.Write([]byte("{{" + tag + "}}"))
+		}
+		return w.Write([]byte(replacement))
+	})
+}
+
+// slugify converts value into a valid DNS label (RFC 1123) of at most maxLength characters: it is lowercased, runs
+// of invalid characters are replaced with a single '-', and leading and trailing dashes are removed.
+func slugify(value string, maxLength int) string {
+	slug := invalidSlugChars.ReplaceAllString(strings.ToLower(value), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength], "-")
+	}
+	return slug
+}
+
+// shortSHA abbreviates a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > shortSHALength {
+		return sha[:shortSHALength]
+	}
+	return sha
+}
+
+// formatPullRequestTime renders a pull request timestamp as RFC 3339, or an empty string if the provider did not report it.
+func formatPullRequestTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 // selectServiceProvider selects the provider to get pull requests from the configuration
 func (g *PullRequestGenerator) selectServiceProvider(ctx context.Context, generatorConfig *argoprojiov1alpha1.PullRequestGenerator, applicationSetInfo *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
 	if generatorConfig.Github != nil {
 		providerConfig := generatorConfig.Github
-		token, err := g.getSecretRef(ctx, providerConfig.TokenRef, applicationSetInfo.Namespace)
+		if providerConfig.App != nil {
+			if providerConfig.TokenRef != nil {
+				return nil, fmt.Errorf("only one of tokenRef and app may be set")
+			}
+			if providerConfig.TokenFrom != nil {
+				return nil, fmt.Errorf("only one of tokenFrom and app may be set")
+			}
+			privateKey, err := g.getSecretRef(ctx, &providerConfig.App.PrivateKeyRef, applicationSetInfo.Namespace)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching Secret private key: %v", err)
+			}
+			return pullrequest.NewGithubAppService(ctx, g.githubAppTransports, []byte(privateKey), providerConfig)
+		}
+		token, err := g.getToken(ctx, providerConfig.TokenRef, providerConfig.TokenFrom, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching token: %v", err)
+		}
+		return pullrequest.NewGithubService(ctx, token, providerConfig)
+	}
+	if generatorConfig.Gerrit != nil {
+		providerConfig := generatorConfig.Gerrit
+		password, err := g.getSecretRef(ctx, providerConfig.PasswordRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Secret password: %v", err)
+		}
+		return pullrequest.NewGerritService(ctx, providerConfig.Username, password, providerConfig.API, providerConfig.Project, providerConfig.Proxy)
+	}
+	if generatorConfig.Plugin != nil {
+		providerConfig := generatorConfig.Plugin
+		baseURL, err := g.getConfigMapValue(ctx, providerConfig.ConfigMapRef, pluginBaseURLKey, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching plugin ConfigMap: %v", err)
+		}
+		token, err := g.getToken(ctx, providerConfig.TokenRef, providerConfig.TokenFrom, applicationSetInfo.Namespace)
 		if err != nil {
-			return nil, fmt.Errorf("error fetching Secret token: %v", err)
+			return nil, fmt.Errorf("error fetching token: %v", err)
 		}
-		return pullrequest.NewGithubService(ctx, token, providerConfig.API, providerConfig.Owner, providerConfig.Repo, providerConfig.Labels)
+		return pullrequest.NewPluginService(ctx, baseURL, token, providerConfig.Input)
 	}
 	return nil, fmt.Errorf("no Pull Request provider implementation configured")
 }
 
-// getSecretRef gets the value of the key for the specified Secret resource.
-func (g *PullRequestGenerator) getSecretRef(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
-	if ref == nil {
-		return "", nil
+// getToken gets the token from the controller as specified by tokenFrom, or else from the Secret referenced by ref.
+func (g *PullRequestGenerator) getToken(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, tokenFrom *argoprojiov1alpha1.TokenSource, namespace string) (string, error) {
+	if tokenFrom == nil {
+		return g.getSecretRef(ctx, ref, namespace)
+	}
+	if ref != nil {
+		return "", fmt.Errorf("only one of tokenRef and tokenFrom may be set")
+	}
+	if g.tokenReader == nil {
+		return "", fmt.Errorf("tokenFrom is not supported by this controller")
 	}
+	return g.tokenReader.Read(ctx, tokenFrom)
+}
+
+// getSecretRef gets the value of the key for the specified Secret resource. The secret is looked up in namespace,
+// unless the reference names another namespace allowed by the controller.
+func (g *PullRequestGenerator) getSecretRef(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
+	return getSecretRef(ctx, g.client, g.secretReader, g.allowedSecretNamespaces, ref, namespace)
+}
 
-	secret := &corev1.Secret{}
+// getConfigMapValue gets the value of the key in the specified ConfigMap resource.
+func (g *PullRequestGenerator) getConfigMapValue(ctx context.Context, name, key, namespace string) (string, error) {
+	configMap := &corev1.ConfigMap{}
 	err := g.client.Get(
 		ctx,
 		client.ObjectKey{
-			Name:      ref.SecretName,
+			Name:      name,
 			Namespace: namespace,
 		},
-		secret)
+		configMap)
 	if err != nil {
-		return "", fmt.Errorf("error fetching secret %s/%s: %v", namespace, ref.SecretName, err)
+		return "", fmt.Errorf("error fetching configmap %s/%s: %v", namespace, name, err)
 	}
-	tokenBytes, ok := secret.Data[ref.Key]
+	value, ok := configMap.Data[key]
 	if !ok {
-		return "", fmt.Errorf("key %q in secret %s/%s not found", ref.Key, namespace, ref.SecretName)
+		return "", fmt.Errorf("key %q in configmap %s/%s not found", key, namespace, name)
 	}
-	return string(tokenBytes), nil
+	return value, nil
 }
@@ -3,7 +3,10 @@ package generators
 import (
 	"context"
 	"errors"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +15,7 @@ import (
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
 	pullrequest "github.com/argoproj/applicationset/pkg/services/pull_request"
+	"github.com/argoproj/applicationset/pkg/utils"
 )
 
 func TestPullRequestGithubGenerateParams(t *testing.T) {
@@ -27,9 +31,16 @@ func TestPullRequestGithubGenerateParams(t *testing.T) {
 					ctx,
 					[]*pullrequest.PullRequest{
 						&pullrequest.PullRequest{
-							Number:  1,
-							Branch:  "branch1",
-							HeadSHA: "089d92cbf9ff857a39e6feccd32798ca700fb958",
+							Number:       1,
+							Branch:       "branch1",
+							HeadSHA:      "089d92cbf9ff857a39e6feccd32798ca700fb958",
+							Title:        "Add feature",
+							Author:       "octocat",
+							URL:          "https://github.com/myorg/myrepo/pull/1",
+							TargetBranch: "main",
+							CreatedAt:    time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+							UpdatedAt:    time.Date(2021, 11, 3, 12, 30, 0, 0, time.UTC),
+							Labels:       []string{"preview", "team-a"},
 						},
 					},
 					nil,
@@ -37,9 +48,20 @@ func TestPullRequestGithubGenerateParams(t *testing.T) {
 			},
 			expected: []map[string]string{
 				{
-					"number":   "1",
-					"branch":   "branch1",
-					"head_sha": "089d92cbf9ff857a39e6feccd32798ca700fb958",
+					"number":         "1",
+					"branch":         "branch1",
+					"branch_slug":    "branch1",
+					"head_sha":       "089d92cbf9ff857a39e6feccd32798ca700fb958",
+					"head_short_sha": "089d92cb",
+					"title":          "Add feature",
+					"author":         "octocat",
+					"url":            "https://github.com/myorg/myrepo/pull/1",
+					"target_branch":  "main",
+					"created_at":     "2021-11-02T10:00:00Z",
+					"updated_at":     "2021-11-03T12:30:00Z",
+					"labels":         "preview,team-a",
+					"labels.preview": "true",
+					"labels.team-a":  "true",
 				},
 			},
 			expectedErr: nil,
@@ -65,11 +87,69 @@ func TestPullRequestGithubGenerateParams(t *testing.T) {
 			PullRequest: &argoprojiov1alpha1.PullRequestGenerator{},
 		}
 		got, gotErr := gen.GenerateParams(&generatorConfig, nil)
-		assert.Equal(t, c.expectedErr, gotErr)
+		if c.expectedErr != nil {
+			assert.EqualError(t, gotErr, c.expectedErr.Error())
+		} else {
+			assert.NoError(t, gotErr)
+		}
 		assert.ElementsMatch(t, c.expected, got)
 	}
 }
 
+func TestPullRequestGenerateParamsValues(t *testing.T) {
+	ctx := context.Background()
+	gen := PullRequestGenerator{
+		selectServiceProviderFunc: func(context.Context, *argoprojiov1alpha1.PullRequestGenerator, *argoprojiov1alpha1.ApplicationSet) (pullrequest.PullRequestService, error) {
+			return pullrequest.NewFakeService(
+				ctx,
+				[]*pullrequest.PullRequest{
+					{
+						Number:  1,
+						Branch:  "Feature/Login",
+						HeadSHA: "089d92cbf9ff857a39e6feccd32798ca700fb958",
+					},
+				},
+				nil,
+			)
+		},
+	}
+	generatorConfig := argoprojiov1alpha1.ApplicationSetGenerator{
+		PullRequest: &argoprojiov1alpha1.PullRequestGenerator{
+			Values: map[string]string{
+				"url":     "https://{{branch_slug}}.preview.example.com",
+				"image":   "registry.example.com/app:{{ head_short_sha }}",
+				"cluster": "{{ values.url }} {{ unknown }}",
+			},
+		},
+	}
+
+	got, err := gen.GenerateParams(&generatorConfig, nil)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, "https://feature-login.preview.example.com", got[0]["values.url"])
+	assert.Equal(t, "registry.example.com/app:089d92cb", got[0]["values.image"])
+	assert.Equal(t, "{{ values.url }} {{ unknown }}", got[0]["values.cluster"])
+}
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "main", expected: "main"},
+		{value: "Feature/Add_Login", expected: "feature-add-login"},
+		{value: "refs/changes/45/12345/3", expected: "refs-changes-45-12345-3"},
+		{value: "--fix..typo--", expected: "fix-typo"},
+		{value: "feature/" + strings.Repeat("a", 41) + "-b", expected: "feature-" + strings.Repeat("a", 41)},
+		{value: "__", expected: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			assert.Equal(t, c.expected, slugify(c.value, branchSlugMaxLength))
+		})
+	}
+}
+
 func TestPullRequestGetSecretRef(t *testing.T) {
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test"},
@@ -134,3 +214,109 @@ func TestPullRequestGetSecretRef(t *testing.T) {
 		})
 	}
 }
+
+func TestPullRequestGithubAppSelectServiceProvider(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-app", Namespace: "test"},
+		Data: map[string][]byte{
+			"private-key": []byte("not a key"),
+		},
+	}
+	gen := &PullRequestGenerator{client: fake.NewClientBuilder().WithObjects(secret).Build()}
+	appSet := &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Namespace: "test"}}
+	app := &argoprojiov1alpha1.PullRequestGeneratorGithubApp{
+		AppID:          123,
+		InstallationID: 456,
+		PrivateKeyRef:  argoprojiov1alpha1.SecretRef{SecretName: "github-app", Key: "private-key"},
+	}
+
+	_, err := gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		Github: &argoprojiov1alpha1.PullRequestGeneratorGithub{
+			Owner:    "myorg",
+			Repo:     "myrepo",
+			TokenRef: &argoprojiov1alpha1.SecretRef{SecretName: "github-token", Key: "token"},
+			App:      app,
+		},
+	}, appSet)
+	assert.EqualError(t, err, "only one of tokenRef and app may be set")
+
+	_, err = gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		Github: &argoprojiov1alpha1.PullRequestGeneratorGithub{
+			Owner: "myorg",
+			Repo:  "myrepo",
+			App:   app,
+		},
+	}, appSet)
+	assert.Contains(t, err.Error(), "error creating GitHub App transport")
+}
+
+func TestPullRequestPluginSelectServiceProvider(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "review-plugin", Namespace: "test"},
+		Data: map[string]string{
+			"baseUrl": "http://review-plugin.test.svc",
+		},
+	}
+	gen := &PullRequestGenerator{client: fake.NewClientBuilder().WithObjects(configMap).Build()}
+	appSet := &argoprojiov1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Namespace: "test"}}
+
+	svc, err := gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		Plugin: &argoprojiov1alpha1.PullRequestGeneratorPlugin{ConfigMapRef: "review-plugin"},
+	}, appSet)
+	assert.NoError(t, err)
+	assert.IsType(t, &pullrequest.PluginService{}, svc)
+
+	_, err = gen.selectServiceProvider(context.Background(), &argoprojiov1alpha1.PullRequestGenerator{
+		Plugin: &argoprojiov1alpha1.PullRequestGeneratorPlugin{ConfigMapRef: "missing"},
+	}, appSet)
+	assert.Error(t, err)
+}
+
+func TestPullRequestGetToken(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test"},
+		Data: map[string][]byte{
+			"my-token": []byte("secret"),
+		},
+	}
+	gen := &PullRequestGenerator{client: fake.NewClientBuilder().WithObjects(secret).Build(), tokenReader: &utils.TokenReader{}}
+	ctx := context.Background()
+	os.Setenv("ARGOCD_APPSET_TOKEN_TEST", "env-token")
+	defer os.Unsetenv("ARGOCD_APPSET_TOKEN_TEST")
+	ref := &argoprojiov1alpha1.SecretRef{SecretName: "test-secret", Key: "my-token"}
+	tokenFrom := &argoprojiov1alpha1.TokenSource{Env: "ARGOCD_APPSET_TOKEN_TEST"}
+
+	token, err := gen.getToken(ctx, ref, nil, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", token)
+
+	token, err = gen.getToken(ctx, nil, tokenFrom, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", token)
+
+	_, err = gen.getToken(ctx, ref, tokenFrom, "test")
+	assert.EqualError(t, err, "only one of tokenRef and tokenFrom may be set")
+}
+
+func TestPullRequestGetSecretRefOtherNamespace(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "scm-credentials", Namespace: "platform"},
+		Data: map[string][]byte{
+			"token": []byte("secret"),
+		},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+	gen := &PullRequestGenerator{
+		client:                  fake.NewClientBuilder().Build(),
+		secretReader:            reader,
+		allowedSecretNamespaces: []string{"platform"},
+	}
+	ctx := context.Background()
+
+	token, err := gen.getSecretRef(ctx, &argoprojiov1alpha1.SecretRef{SecretName: "scm-credentials", Key: "token", Namespace: "platform"}, "argocd")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", token)
+
+	_, err = gen.getSecretRef(ctx, &argoprojiov1alpha1.SecretRef{SecretName: "scm-credentials", Key: "token", Namespace: "kube-system"}, "argocd")
+	assert.EqualError(t, err, `secret kube-system/scm-credentials can't be used: namespace "kube-system" is not allowed by the controller`)
+}
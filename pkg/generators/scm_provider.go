@@ -2,15 +2,16 @@ package generators
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
 	"github.com/argoproj/applicationset/pkg/services/scm_provider"
+	"github.com/argoproj/applicationset/pkg/utils"
 )
 
 var _ Generator = (*SCMProviderGenerator)(nil)
@@ -21,12 +22,23 @@ const (
 
 type SCMProviderGenerator struct {
 	client client.Client
+	// tokenReader reads the tokens referenced by tokenFrom.
+	tokenReader *utils.TokenReader
+	// secretReader reads secrets from allowedSecretNamespaces, which the cache of client doesn't cover.
+	secretReader client.Reader
+	// allowedSecretNamespaces are the namespaces other than the ApplicationSet's that secrets may be read from.
+	allowedSecretNamespaces []string
 	// Testing hooks.
 	overrideProvider scm_provider.SCMProviderService
 }
 
-func NewSCMProviderGenerator(client client.Client) Generator {
-	return &SCMProviderGenerator{client: client}
+func NewSCMProviderGenerator(client client.Client, tokenReader *utils.TokenReader, secretReader client.Reader, allowedSecretNamespaces []string) Generator {
+	return &SCMProviderGenerator{
+		client:                  client,
+		tokenReader:             tokenReader,
+		secretReader:            secretReader,
+		allowedSecretNamespaces: allowedSecretNamespaces,
+	}
 }
 
 func (g *SCMProviderGenerator) GetRequeueAfter(appSetGenerator *argoprojiov1alpha1.ApplicationSetGenerator) time.Duration {
@@ -60,7 +72,7 @@ func (g *SCMProviderGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha
 	if g.overrideProvider != nil {
 		provider = g.overrideProvider
 	} else if providerConfig.Github != nil {
-		token, err := g.getSecretRef(ctx, providerConfig.Github.TokenRef, applicationSetInfo.Namespace)
+		token, err := g.getToken(ctx, providerConfig.Github.TokenRef, providerConfig.Github.TokenFrom, applicationSetInfo.Namespace)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching Github token: %v", err)
 		}
@@ -69,7 +81,7 @@ func (g *SCMProviderGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha
 			return nil, fmt.Errorf("error initializing Github service: %v", err)
 		}
 	} else if providerConfig.Gitlab != nil {
-		token, err := g.getSecretRef(ctx, providerConfig.Gitlab.TokenRef, applicationSetInfo.Namespace)
+		token, err := g.getToken(ctx, providerConfig.Gitlab.TokenRef, providerConfig.Gitlab.TokenFrom, applicationSetInfo.Namespace)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching Gitlab token: %v", err)
 		}
@@ -77,14 +89,70 @@ func (g *SCMProviderGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha
 		if err != nil {
 			return nil, fmt.Errorf("error initializing Gitlab service: %v", err)
 		}
+	} else if providerConfig.BitbucketServer != nil {
+		password, err := g.getSecretRef(ctx, providerConfig.BitbucketServer.PasswordRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Bitbucket Server password: %v", err)
+		}
+		token, err := g.getToken(ctx, providerConfig.BitbucketServer.TokenRef, providerConfig.BitbucketServer.TokenFrom, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Bitbucket Server token: %v", err)
+		}
+		provider, err = scm_provider.NewBitbucketServerProvider(ctx, providerConfig.BitbucketServer.Project, providerConfig.BitbucketServer.API, providerConfig.BitbucketServer.Username, password, token, providerConfig.BitbucketServer.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Bitbucket Server service: %v", err)
+		}
+	} else if providerConfig.Gitea != nil {
+		token, err := g.getToken(ctx, providerConfig.Gitea.TokenRef, providerConfig.Gitea.TokenFrom, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Gitea token: %v", err)
+		}
+		provider, err = scm_provider.NewGiteaProvider(ctx, providerConfig.Gitea.Owner, token, providerConfig.Gitea.API, providerConfig.Gitea.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Gitea service: %v", err)
+		}
+	} else if providerConfig.AzureDevOps != nil {
+		token, err := g.getToken(ctx, providerConfig.AzureDevOps.TokenRef, providerConfig.AzureDevOps.TokenFrom, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Azure DevOps token: %v", err)
+		}
+		provider, err = scm_provider.NewAzureDevOpsProvider(ctx, providerConfig.AzureDevOps.Organization, providerConfig.AzureDevOps.Project, token, providerConfig.AzureDevOps.API, providerConfig.AzureDevOps.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Azure DevOps service: %v", err)
+		}
+	} else if providerConfig.AWSCodeCommit != nil {
+		var err error
+		provider, err = scm_provider.NewAWSCodeCommitProvider(ctx, providerConfig.AWSCodeCommit.TagFilters, providerConfig.AWSCodeCommit.Role, providerConfig.AWSCodeCommit.Region, providerConfig.AWSCodeCommit.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing AWS CodeCommit service: %v", err)
+		}
+	} else if providerConfig.Gerrit != nil {
+		password, err := g.getSecretRef(ctx, providerConfig.Gerrit.PasswordRef, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Gerrit password: %v", err)
+		}
+		provider, err = scm_provider.NewGerritProvider(ctx, providerConfig.Gerrit.API, providerConfig.Gerrit.Prefix, providerConfig.Gerrit.Username, password, providerConfig.Gerrit.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Gerrit service: %v", err)
+		}
+	} else if providerConfig.Gogs != nil {
+		token, err := g.getToken(ctx, providerConfig.Gogs.TokenRef, providerConfig.Gogs.TokenFrom, applicationSetInfo.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Gogs token: %v", err)
+		}
+		provider, err = scm_provider.NewGogsProvider(ctx, providerConfig.Gogs.Organization, token, providerConfig.Gogs.API, providerConfig.Gogs.AllBranches)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Gogs service: %v", err)
+		}
 	} else {
 		return nil, fmt.Errorf("no SCM provider implementation configured")
 	}
 
 	// Find all the available repos.
-	repos, err := scm_provider.ListRepos(ctx, provider, providerConfig.Filters, providerConfig.CloneProtocol)
-	if err != nil {
-		return nil, fmt.Errorf("error listing repos: %v", err)
+	repos, err := scm_provider.ListRepos(ctx, provider, providerConfig.Filters, providerConfig.CloneProtocol, providerConfig.ContinueOnError)
+	var repoErrs *scm_provider.RepositoryErrors
+	if err != nil && !(providerConfig.ContinueOnError && errors.As(err, &repoErrs)) {
+		return nil, fmt.Errorf("error listing repos: %w", err)
 	}
 	params := make([]map[string]string, 0, len(repos))
 	for _, repo := range repos {
@@ -97,28 +165,26 @@ func (g *SCMProviderGenerator) GenerateParams(appSetGenerator *argoprojiov1alpha
 			"labels":       strings.Join(repo.Labels, ","),
 		})
 	}
+	if repoErrs != nil {
+		return params, &PartialParamsError{Err: fmt.Errorf("error reading repos, which were skipped: %v", repoErrs)}
+	}
 	return params, nil
 }
 
-func (g *SCMProviderGenerator) getSecretRef(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
-	if ref == nil {
-		return "", nil
+// getToken gets the token from the controller as specified by tokenFrom, or else from the Secret referenced by ref.
+func (g *SCMProviderGenerator) getToken(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, tokenFrom *argoprojiov1alpha1.TokenSource, namespace string) (string, error) {
+	if tokenFrom == nil {
+		return g.getSecretRef(ctx, ref, namespace)
 	}
-
-	secret := &corev1.Secret{}
-	err := g.client.Get(
-		ctx,
-		client.ObjectKey{
-			Name:      ref.SecretName,
-			Namespace: namespace,
-		},
-		secret)
-	if err != nil {
-		return "", fmt.Errorf("error fetching secret %s/%s: %v", namespace, ref.SecretName, err)
+	if ref != nil {
+		return "", fmt.Errorf("only one of tokenRef and tokenFrom may be set")
 	}
-	tokenBytes, ok := secret.Data[ref.Key]
-	if !ok {
-		return "", fmt.Errorf("key %q in secret %s/%s not found", ref.Key, namespace, ref.SecretName)
+	if g.tokenReader == nil {
+		return "", fmt.Errorf("tokenFrom is not supported by this controller")
 	}
-	return string(tokenBytes), nil
+	return g.tokenReader.Read(ctx, tokenFrom)
+}
+
+func (g *SCMProviderGenerator) getSecretRef(ctx context.Context, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
+	return getSecretRef(ctx, g.client, g.secretReader, g.allowedSecretNamespaces, ref, namespace)
 }
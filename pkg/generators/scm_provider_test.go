@@ -2,6 +2,7 @@ package generators
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,3 +114,65 @@ func TestSCMProviderGenerateParams(t *testing.T) {
 	assert.Equal(t, "prod,staging", params[0]["labels"])
 	assert.Equal(t, "repo2", params[1]["repository"])
 }
+
+func TestSCMProviderGenerateParamsContinueOnError(t *testing.T) {
+	mockProvider := &scm_provider.MockProvider{
+		Repos: []*scm_provider.Repository{
+			{
+				Organization: "myorg",
+				Repository:   "repo1",
+				Branch:       "main",
+			},
+			{
+				Organization: "myorg",
+				Repository:   "repo2",
+				Branch:       "main",
+			},
+		},
+		PathErrors: map[string]error{
+			"repo1": errors.New("timeout"),
+		},
+	}
+	gen := &SCMProviderGenerator{overrideProvider: mockProvider}
+	generatorConfig := &argoprojiov1alpha1.SCMProviderGenerator{
+		Filters: []argoprojiov1alpha1.SCMProviderGeneratorFilter{
+			{
+				PathsExist: []string{"repo2"},
+			},
+		},
+	}
+
+	_, err := gen.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{SCMProvider: generatorConfig}, nil)
+	assert.EqualError(t, err, "error listing repos: error filtering myorg/repo1@main: timeout")
+
+	generatorConfig.ContinueOnError = true
+	params, err := gen.GenerateParams(&argoprojiov1alpha1.ApplicationSetGenerator{SCMProvider: generatorConfig}, nil)
+	var partialErr *PartialParamsError
+	assert.True(t, errors.As(err, &partialErr))
+	assert.EqualError(t, err, "error reading repos, which were skipped: error filtering myorg/repo1@main: timeout")
+	assert.Len(t, params, 1)
+	assert.Equal(t, "repo2", params[0]["repository"])
+}
+
+func TestSCMProviderGetSecretRefOtherNamespace(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "scm-credentials", Namespace: "platform"},
+		Data: map[string][]byte{
+			"token": []byte("secret"),
+		},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+	gen := &SCMProviderGenerator{
+		client:                  fake.NewClientBuilder().Build(),
+		secretReader:            reader,
+		allowedSecretNamespaces: []string{"platform"},
+	}
+	ctx := context.Background()
+
+	token, err := gen.getSecretRef(ctx, &argoprojiov1alpha1.SecretRef{SecretName: "scm-credentials", Key: "token", Namespace: "platform"}, "argocd")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", token)
+
+	_, err = gen.getSecretRef(ctx, &argoprojiov1alpha1.SecretRef{SecretName: "scm-credentials", Key: "token", Namespace: "kube-system"}, "argocd")
+	assert.EqualError(t, err, `secret kube-system/scm-credentials can't be used: namespace "kube-system" is not allowed by the controller`)
+}
@@ -0,0 +1,49 @@
+package generators
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/utils"
+)
+
+// getSecretRef gets the value of the key of the secret referenced by ref. The secret is read with c from namespace,
+// the namespace of the ApplicationSet, unless ref names another namespace. Secrets of other namespaces are read with
+// secretReader, and only if the namespace is one of allowedSecretNamespaces.
+func getSecretRef(ctx context.Context, c client.Client, secretReader client.Reader, allowedSecretNamespaces []string, ref *argoprojiov1alpha1.SecretRef, namespace string) (string, error) {
+	if ref == nil {
+		return "", nil
+	}
+
+	var reader client.Reader = c
+	if ref.Namespace != "" && ref.Namespace != namespace {
+		if !utils.ContainsString(allowedSecretNamespaces, ref.Namespace) {
+			return "", fmt.Errorf("secret %s/%s can't be used: namespace %q is not allowed by the controller", ref.Namespace, ref.SecretName, ref.Namespace)
+		}
+		namespace = ref.Namespace
+		if secretReader != nil {
+			reader = secretReader
+		}
+	}
+
+	secret := &corev1.Secret{}
+	err := reader.Get(
+		ctx,
+		client.ObjectKey{
+			Name:      ref.SecretName,
+			Namespace: namespace,
+		},
+		secret)
+	if err != nil {
+		return "", fmt.Errorf("error fetching secret %s/%s: %v", namespace, ref.SecretName, err)
+	}
+	tokenBytes, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %q in secret %s/%s not found", ref.Key, namespace, ref.SecretName)
+	}
+	return string(tokenBytes), nil
+}
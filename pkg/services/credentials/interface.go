@@ -0,0 +1,15 @@
+package credentials
+
+import "context"
+
+// SecretReader reads secrets, such as the tokens of SCM providers, from an external secret store at call time.
+type SecretReader interface {
+	// ReadSecret gets the value of key in the secret at path.
+	ReadSecret(ctx context.Context, path, key string) (string, error)
+}
+
+// TokenExchanger exchanges the identity of the controller for tokens, such as the tokens of SCM providers.
+type TokenExchanger interface {
+	// Exchange gets a token for scope, issued under the trust policy named identity.
+	Exchange(ctx context.Context, scope, identity string) (string, error)
+}
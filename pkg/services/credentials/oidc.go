@@ -0,0 +1,138 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// oidcTokenLifetime is assumed for exchanged tokens whose expiry is not returned. GitHub App installation
+	// tokens are valid for an hour.
+	oidcTokenLifetime = time.Hour
+	// oidcTokenRefreshBefore is how long before they expire exchanged tokens stop being reused.
+	oidcTokenRefreshBefore = 5 * time.Minute
+)
+
+// OIDCTokenExchanger exchanges the controller's service account token, an OIDC identity token, for short-lived SCM
+// tokens at a security token service, such as Octo STS for GitHub. The service decides which tokens to issue
+// based on trust policies, so no SCM credentials need to be stored. Exchanged tokens are reused until shortly
+// before they expire. An OIDCTokenExchanger is safe for concurrent use.
+type OIDCTokenExchanger struct {
+	client *http.Client
+	// exchangeURL is the exchange endpoint of the security token service.
+	exchangeURL *url.URL
+	// serviceAccountTokenPath is the file containing the identity token, typically a projected service account
+	// token whose audience is the security token service.
+	serviceAccountTokenPath string
+
+	// mu guards tokens. It is not held while tokens are exchanged, so that a slow exchange doesn't hold up the
+	// reuse of other tokens.
+	mu     sync.Mutex
+	tokens map[string]*oidcExchangedToken
+}
+
+var _ TokenExchanger = (*OIDCTokenExchanger)(nil)
+
+type oidcExchangedToken struct {
+	token  string
+	expiry time.Time
+}
+
+// oidcExchangeResponse is the body returned by the exchange endpoint.
+type oidcExchangeResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewOIDCTokenExchanger(exchangeURL, serviceAccountTokenPath string) (*OIDCTokenExchanger, error) {
+	if exchangeURL == "" {
+		return nil, fmt.Errorf("token exchange URL is required")
+	}
+	parsedURL, err := url.Parse(exchangeURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token exchange URL: %v", err)
+	}
+	if serviceAccountTokenPath == "" {
+		serviceAccountTokenPath = DefaultServiceAccountTokenPath
+	}
+	return &OIDCTokenExchanger{
+		client:                  &http.Client{Timeout: 30 * time.Second},
+		exchangeURL:             parsedURL,
+		serviceAccountTokenPath: serviceAccountTokenPath,
+		tokens:                  map[string]*oidcExchangedToken{},
+	}, nil
+}
+
+// Exchange returns a token for scope, such as a GitHub organization or repository, issued under the trust policy
+// named identity.
+func (e *OIDCTokenExchanger) Exchange(ctx context.Context, scope, identity string) (string, error) {
+	key := scope + "\n" + identity
+	e.mu.Lock()
+	cached, ok := e.tokens[key]
+	e.mu.Unlock()
+	if ok && time.Until(cached.expiry) > oidcTokenRefreshBefore {
+		return cached.token, nil
+	}
+
+	exchanged, err := e.exchange(ctx, scope, identity)
+	if err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	e.tokens[key] = exchanged
+	e.mu.Unlock()
+	return exchanged.token, nil
+}
+
+// exchange requests a token for scope under identity from the security token service.
+func (e *OIDCTokenExchanger) exchange(ctx context.Context, scope, identity string) (*oidcExchangedToken, error) {
+	jwt, err := ioutil.ReadFile(e.serviceAccountTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading service account token: %v", err)
+	}
+	// The exchange URL may carry a query of its own, eg to select a tenant of the service.
+	reqURL := *e.exchangeURL
+	query := reqURL.Query()
+	query.Set("scope", scope)
+	query.Set("identity", identity)
+	reqURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(jwt)))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging token for %s: %v", scope, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging token for %s: %v", scope, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error exchanging token for %s: unexpected status %d: %s", scope, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var exchanged oidcExchangeResponse
+	if err := json.Unmarshal(body, &exchanged); err != nil {
+		return nil, fmt.Errorf("error decoding token exchange response: %v", err)
+	}
+	if exchanged.Token == "" {
+		return nil, fmt.Errorf("error exchanging token for %s: no token returned", scope)
+	}
+
+	expiry := exchanged.ExpiresAt
+	if expiry.IsZero() {
+		expiry = time.Now().Add(oidcTokenLifetime)
+	}
+	return &oidcExchangedToken{token: exchanged.Token, expiry: expiry}, nil
+}
@@ -0,0 +1,114 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCTokenExchange(t *testing.T) {
+	exchanges := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts/exchange", r.URL.Path)
+		// The query of the exchange URL is kept.
+		assert.Equal(t, "acme", r.URL.Query().Get("tenant"))
+		assert.Equal(t, "Bearer sa-token", r.Header.Get("Authorization"))
+		exchanges++
+		switch r.URL.Query().Get("scope") {
+		case "myorg/myrepo":
+			assert.Equal(t, "applicationset", r.URL.Query().Get("identity"))
+			fmt.Fprintf(w, `{"token": "ghs_%d"}`, exchanges)
+		case "myorg/expiring":
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, exchanges, time.Now().Add(time.Minute).Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "no matching trust policy")
+		}
+	}))
+	defer ts.Close()
+
+	tokenFile, err := ioutil.TempFile("", "sa-token")
+	assert.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	_, err = tokenFile.WriteString("sa-token\n")
+	assert.NoError(t, err)
+	tokenFile.Close()
+
+	exchanger, err := NewOIDCTokenExchanger(ts.URL+"/sts/exchange?tenant=acme", tokenFile.Name())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	token, err := exchanger.Exchange(ctx, "myorg/myrepo", "applicationset")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+	// Tokens are reused until shortly before they expire.
+	token, err = exchanger.Exchange(ctx, "myorg/myrepo", "applicationset")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	token, err = exchanger.Exchange(ctx, "myorg/expiring", "applicationset")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_2", token)
+	token, err = exchanger.Exchange(ctx, "myorg/expiring", "applicationset")
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_3", token)
+
+	_, err = exchanger.Exchange(ctx, "otherorg/repo", "applicationset")
+	assert.EqualError(t, err, "error exchanging token for otherorg/repo: unexpected status 403: no matching trust policy")
+}
+
+func TestOIDCSlowExchangeDoesNotBlockCachedTokens(t *testing.T) {
+	slowStarted := make(chan struct{})
+	slow := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := r.URL.Query().Get("scope")
+		if scope == "myorg/slow" {
+			close(slowStarted)
+			<-slow
+		}
+		fmt.Fprintf(w, `{"token": "token-for-%s"}`, scope)
+	}))
+	defer ts.Close()
+
+	tokenFile, err := ioutil.TempFile("", "sa-token")
+	assert.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	tokenFile.Close()
+
+	exchanger, err := NewOIDCTokenExchanger(ts.URL, tokenFile.Name())
+	assert.NoError(t, err)
+	ctx := context.Background()
+	_, err = exchanger.Exchange(ctx, "myorg/cached", "applicationset")
+	assert.NoError(t, err)
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		token, err := exchanger.Exchange(ctx, "myorg/slow", "applicationset")
+		assert.NoError(t, err)
+		assert.Equal(t, "token-for-myorg/slow", token)
+	}()
+	<-slowStarted
+
+	cachedDone := make(chan struct{})
+	go func() {
+		defer close(cachedDone)
+		token, err := exchanger.Exchange(ctx, "myorg/cached", "applicationset")
+		assert.NoError(t, err)
+		assert.Equal(t, "token-for-myorg/cached", token)
+	}()
+	select {
+	case <-cachedDone:
+	case <-time.After(5 * time.Second):
+		t.Error("cached token blocked by a slow exchange")
+	}
+	close(slow)
+	<-slowDone
+}
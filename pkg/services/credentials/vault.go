@@ -0,0 +1,261 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultVaultAuthMount is the path Vault's Kubernetes auth method is enabled at by default.
+	DefaultVaultAuthMount = "kubernetes"
+	// DefaultServiceAccountTokenPath is where Kubernetes mounts the token of the pod's service account.
+	DefaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// vaultSecretTTL is the longest a secret is cached, so that rotated tokens are picked up.
+	vaultSecretTTL = 5 * time.Minute
+	// vaultTokenRenewBefore is how long before it expires the Vault token is renewed.
+	vaultTokenRenewBefore = time.Minute
+)
+
+// VaultConfig describes how to log in to HashiCorp Vault.
+type VaultConfig struct {
+	// Address of the Vault server, eg https://vault.example.com:8200.
+	Address string
+	// AuthMount is the path the Kubernetes auth method is enabled at. Defaults to DefaultVaultAuthMount.
+	AuthMount string
+	// Role is the Vault role to log in as.
+	Role string
+	// ServiceAccountTokenPath is the file containing the service account token to log in with. Defaults to
+	// DefaultServiceAccountTokenPath.
+	ServiceAccountTokenPath string
+}
+
+// VaultClient reads secrets from HashiCorp Vault, logging in with the Kubernetes auth method. Its Vault token is
+// renewed before it expires, or replaced by logging in again if it can't be, and secrets are cached for at most
+// their lease duration. A VaultClient is safe for concurrent use.
+type VaultClient struct {
+	client *http.Client
+	config VaultConfig
+
+	// mu guards secrets. It is not held while secrets are read from Vault, so that a slow read doesn't hold up
+	// the reads of cached secrets.
+	mu      sync.Mutex
+	secrets map[string]*vaultCachedSecret
+
+	// tokenMu guards the Vault token. It is held while the token is renewed or replaced, so that only one login
+	// happens at a time.
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	renewable   bool
+}
+
+var _ SecretReader = (*VaultClient)(nil)
+
+type vaultCachedSecret struct {
+	data   map[string]interface{}
+	expiry time.Time
+}
+
+// vaultResponse is the subset of the responses of the Vault API used by the client.
+type vaultResponse struct {
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// vaultStatusError is returned for responses of the Vault API with an unexpected status code.
+type vaultStatusError struct {
+	status int
+	errors []string
+}
+
+func (e *vaultStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.status, strings.Join(e.errors, "; "))
+}
+
+func NewVaultClient(config VaultConfig) (*VaultClient, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if config.Role == "" {
+		return nil, fmt.Errorf("vault role is required")
+	}
+	if config.AuthMount == "" {
+		config.AuthMount = DefaultVaultAuthMount
+	}
+	if config.ServiceAccountTokenPath == "" {
+		config.ServiceAccountTokenPath = DefaultServiceAccountTokenPath
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	return &VaultClient{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		config:  config,
+		secrets: map[string]*vaultCachedSecret{},
+	}, nil
+}
+
+func (v *VaultClient) ReadSecret(ctx context.Context, path, key string) (string, error) {
+	path = strings.Trim(path, "/")
+	data, err := v.readSecretData(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("error reading vault secret %s: %v", path, err)
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q in vault secret %s not found", key, path)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %q in vault secret %s is not a string", key, path)
+	}
+	return str, nil
+}
+
+func (v *VaultClient) readSecretData(ctx context.Context, path string) (map[string]interface{}, error) {
+	v.mu.Lock()
+	cached, ok := v.secrets[path]
+	v.mu.Unlock()
+	if ok && time.Now().Before(cached.expiry) {
+		return cached.data, nil
+	}
+
+	token, err := v.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.do(ctx, http.MethodGet, "/v1/"+path, token, nil)
+	if statusErr, ok := err.(*vaultStatusError); ok && statusErr.status == http.StatusForbidden {
+		// The token may have been revoked, try again with a new one.
+		v.discardToken(token)
+		if token, err = v.getToken(ctx); err != nil {
+			return nil, err
+		}
+		resp, err = v.do(ctx, http.MethodGet, "/v1/"+path, token, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := resp.Data
+	// Secrets of the KV version 2 engine are nested, along with their metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	ttl := vaultSecretTTL
+	if lease := time.Duration(resp.LeaseDuration) * time.Second; lease > 0 && lease < ttl {
+		ttl = lease
+	}
+	v.mu.Lock()
+	v.secrets[path] = &vaultCachedSecret{data: data, expiry: time.Now().Add(ttl)}
+	v.mu.Unlock()
+	return data, nil
+}
+
+// discardToken forgets the Vault token, unless it was already replaced by another reader.
+func (v *VaultClient) discardToken(token string) {
+	v.tokenMu.Lock()
+	defer v.tokenMu.Unlock()
+	if v.token == token {
+		v.token = ""
+	}
+}
+
+// getToken returns a valid Vault token, renewing the current one or logging in as needed.
+func (v *VaultClient) getToken(ctx context.Context) (string, error) {
+	v.tokenMu.Lock()
+	defer v.tokenMu.Unlock()
+
+	if v.token != "" && time.Until(v.tokenExpiry) > vaultTokenRenewBefore {
+		return v.token, nil
+	}
+	if v.token != "" && v.renewable && time.Now().Before(v.tokenExpiry) {
+		resp, err := v.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", v.token, map[string]interface{}{})
+		if err == nil && resp.Auth != nil {
+			v.setToken(resp)
+			return v.token, nil
+		}
+		// Tokens past their max TTL can't be renewed anymore, so fall back to logging in again.
+	}
+
+	jwt, err := ioutil.ReadFile(v.config.ServiceAccountTokenPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading service account token: %v", err)
+	}
+	resp, err := v.do(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", strings.Trim(v.config.AuthMount, "/")), "", map[string]interface{}{
+		"role": v.config.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error logging in to vault as role %s: %v", v.config.Role, err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("error logging in to vault as role %s: no token returned", v.config.Role)
+	}
+	v.setToken(resp)
+	return v.token, nil
+}
+
+func (v *VaultClient) setToken(resp *vaultResponse) {
+	v.token = resp.Auth.ClientToken
+	v.tokenExpiry = time.Now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	v.renewable = resp.Auth.Renewable
+	// Tokens without a lease, such as root tokens, never expire.
+	if resp.Auth.LeaseDuration == 0 {
+		v.tokenExpiry = time.Now().Add(100 * 365 * 24 * time.Hour)
+	}
+}
+
+func (v *VaultClient) do(ctx context.Context, method, path, token string, body interface{}) (*vaultResponse, error) {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.config.Address+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &vaultResponse{}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, resp); err != nil && httpResp.StatusCode == http.StatusOK {
+			return nil, fmt.Errorf("error decoding response: %v", err)
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &vaultStatusError{status: httpResp.StatusCode, errors: resp.Errors}
+	}
+	return resp, nil
+}
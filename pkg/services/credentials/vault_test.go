@@ -0,0 +1,177 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type vaultMock struct {
+	t             *testing.T
+	logins        int
+	renewals      int
+	reads         int
+	leaseDuration int
+	revoked       map[string]bool
+	// slowStarted is closed when a read of kv/scm/slow starts, which then waits for slow to be closed.
+	slowStarted chan struct{}
+	slow        chan struct{}
+}
+
+func (m *vaultMock) handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/auth/kubernetes/login":
+		var body map[string]string
+		assert.NoError(m.t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(m.t, map[string]string{"role": "applicationset", "jwt": "sa-token"}, body)
+		m.logins++
+		fmt.Fprintf(w, `{"auth": {"client_token": "token-%d", "lease_duration": %d, "renewable": true}}`, m.logins, m.leaseDuration)
+	case "/v1/auth/token/renew-self":
+		m.renewals++
+		fmt.Fprintf(w, `{"auth": {"client_token": %q, "lease_duration": 3600, "renewable": true}}`, r.Header.Get("X-Vault-Token"))
+	case "/v1/secret/data/scm/github":
+		if m.revoked[r.Header.Get("X-Vault-Token")] {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		m.reads++
+		fmt.Fprint(w, `{"lease_duration": 0, "data": {"data": {"token": "github-token", "count": 1}, "metadata": {"version": 3}}}`)
+	case "/v1/kv/scm/slow":
+		close(m.slowStarted)
+		<-m.slow
+		fmt.Fprint(w, `{"lease_duration": 0, "data": {"token": "slow-token"}}`)
+	case "/v1/kv/scm/gitlab":
+		m.reads++
+		fmt.Fprint(w, `{"lease_duration": 2764800, "data": {"token": "gitlab-token"}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": []}`)
+	}
+}
+
+func newTestVaultClient(t *testing.T, mock *vaultMock) (*VaultClient, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(mock.handler))
+	dir, err := ioutil.TempDir("", "vault")
+	assert.NoError(t, err)
+	tokenPath := filepath.Join(dir, "token")
+	assert.NoError(t, ioutil.WriteFile(tokenPath, []byte("sa-token\n"), 0600))
+	client, err := NewVaultClient(VaultConfig{Address: ts.URL + "/", Role: "applicationset", ServiceAccountTokenPath: tokenPath})
+	assert.NoError(t, err)
+	return client, func() {
+		ts.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestVaultReadSecret(t *testing.T) {
+	mock := &vaultMock{t: t, leaseDuration: 3600}
+	client, cleanup := newTestVaultClient(t, mock)
+	defer cleanup()
+	ctx := context.Background()
+
+	token, err := client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "github-token", token)
+
+	token, err = client.ReadSecret(ctx, "/kv/scm/gitlab", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "gitlab-token", token)
+
+	// Cached secrets are not read again, and the token is reused.
+	_, err = client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mock.reads)
+	assert.Equal(t, 1, mock.logins)
+
+	_, err = client.ReadSecret(ctx, "secret/data/scm/github", "missing")
+	assert.EqualError(t, err, `key "missing" in vault secret secret/data/scm/github not found`)
+	_, err = client.ReadSecret(ctx, "secret/data/scm/github", "count")
+	assert.Error(t, err)
+	_, err = client.ReadSecret(ctx, "secret/data/scm/missing", "token")
+	assert.Error(t, err)
+}
+
+func TestVaultTokenRenewal(t *testing.T) {
+	mock := &vaultMock{t: t, leaseDuration: 30}
+	client, cleanup := newTestVaultClient(t, mock)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	// The token expires within vaultTokenRenewBefore, so it is renewed rather than reused.
+	client.secrets = map[string]*vaultCachedSecret{}
+	_, err = client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, mock.logins)
+	assert.Equal(t, 1, mock.renewals)
+	assert.True(t, time.Until(client.tokenExpiry) > 50*time.Minute)
+}
+
+func TestVaultLoginAfterRevocation(t *testing.T) {
+	mock := &vaultMock{t: t, leaseDuration: 3600, revoked: map[string]bool{}}
+	client, cleanup := newTestVaultClient(t, mock)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	mock.revoked["token-1"] = true
+	client.secrets = map[string]*vaultCachedSecret{}
+	token, err := client.ReadSecret(ctx, "secret/data/scm/github", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "github-token", token)
+	assert.Equal(t, 2, mock.logins)
+}
+
+func TestVaultSlowReadDoesNotBlockCachedReads(t *testing.T) {
+	mock := &vaultMock{t: t, leaseDuration: 3600, slowStarted: make(chan struct{}), slow: make(chan struct{})}
+	client, cleanup := newTestVaultClient(t, mock)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := client.ReadSecret(ctx, "kv/scm/gitlab", "token")
+	assert.NoError(t, err)
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		token, err := client.ReadSecret(ctx, "kv/scm/slow", "token")
+		assert.NoError(t, err)
+		assert.Equal(t, "slow-token", token)
+	}()
+	<-mock.slowStarted
+
+	cachedDone := make(chan struct{})
+	go func() {
+		defer close(cachedDone)
+		token, err := client.ReadSecret(ctx, "kv/scm/gitlab", "token")
+		assert.NoError(t, err)
+		assert.Equal(t, "gitlab-token", token)
+	}()
+	select {
+	case <-cachedDone:
+	case <-time.After(5 * time.Second):
+		t.Error("cached read blocked by a slow read")
+	}
+	close(mock.slow)
+	<-slowDone
+}
+
+func TestNewVaultClientRequiresAddressAndRole(t *testing.T) {
+	_, err := NewVaultClient(VaultConfig{Role: "applicationset"})
+	assert.Error(t, err)
+	_, err = NewVaultClient(VaultConfig{Address: "https://vault.example.com"})
+	assert.Error(t, err)
+}
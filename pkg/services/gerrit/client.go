@@ -0,0 +1,96 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// xssiPrefix is prepended by Gerrit to every JSON response body to prevent cross-site script inclusion.
+var xssiPrefix = []byte(")]}'")
+
+// Client makes requests to the REST API of a Gerrit server. If username is empty, requests are made anonymously;
+// otherwise password must be the user's Gerrit HTTP password.
+type Client struct {
+	httpClient *http.Client
+	url        string
+	username   string
+	password   string
+}
+
+// NewClient returns a client of the Gerrit server at url, making its requests with httpClient.
+func NewClient(httpClient *http.Client, url, username, password string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		url:        strings.TrimSuffix(url, "/"),
+		username:   username,
+		password:   password,
+	}
+}
+
+// URL returns the URL of the Gerrit server, without trailing slash.
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Error is returned for a response of the Gerrit REST API with an unexpected status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if err is, or wraps, an Error for a 404 response.
+func IsNotFound(err error) bool {
+	var gerritErr *Error
+	return errors.As(err, &gerritErr) && gerritErr.StatusCode == http.StatusNotFound
+}
+
+// Get makes a GET request to path of the REST API, and decodes the response into out, unless it is nil.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	// Authenticated requests must use the /a/ prefix, or Gerrit silently treats them as anonymous.
+	reqURL := c.url + path
+	if c.username != "" {
+		reqURL = c.url + "/a" + path
+	}
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(bytes.TrimPrefix(body, xssiPrefix), out); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	return nil
+}
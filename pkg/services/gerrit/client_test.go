@@ -0,0 +1,52 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientGet(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/projects/?p=platform":
+			_, _, ok := r.BasicAuth()
+			assert.False(t, ok)
+			fmt.Fprint(w, ")]}'\n{\"platform/web\": {}}")
+		case "/a/projects/?p=platform":
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "jdoe", username)
+			assert.Equal(t, "http-password", password)
+			fmt.Fprint(w, ")]}'\n{\"platform/api\": {}}")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Not found\n")
+		}
+	}))
+	defer ts.Close()
+	query := url.Values{"p": []string{"platform"}}
+
+	var projects map[string]struct{}
+	err := NewClient(&http.Client{}, ts.URL+"/", "", "").Get(context.Background(), "/projects/", query, &projects)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"platform/web": {}}, projects)
+
+	client := NewClient(&http.Client{}, ts.URL+"/", "jdoe", "http-password")
+	assert.Equal(t, ts.URL, client.URL())
+	projects = nil
+	err = client.Get(context.Background(), "/projects/", query, &projects)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"platform/api": {}}, projects)
+
+	err = client.Get(context.Background(), "/projects/missing", nil, nil)
+	assert.EqualError(t, err, "unexpected status 404: Not found")
+	assert.True(t, IsNotFound(err))
+	assert.True(t, IsNotFound(fmt.Errorf("error listing branches: %w", err)))
+	assert.False(t, IsNotFound(&Error{StatusCode: http.StatusForbidden}))
+}
@@ -0,0 +1,144 @@
+package pull_request
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxResponseCacheBytes bounds the total size of the response bodies kept by the shared response cache.
+const maxResponseCacheBytes = 64 << 20
+
+// sharedResponseCache is shared by all pull request services, since they are created anew on every reconcile.
+var sharedResponseCache = newResponseCache(maxResponseCacheBytes)
+
+// cachedResponse is a response body along with the validator to revalidate it with.
+type cachedResponse struct {
+	key    string
+	etag   string
+	status int
+	header http.Header
+	body   []byte
+}
+
+// responseCache is a least recently used cache of responses, bounded by the total size of their bodies.
+type responseCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+func newResponseCache(maxBytes int) *responseCache {
+	return &responseCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+func (c *responseCache) get(key string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*cachedResponse)
+}
+
+func (c *responseCache) add(entry *cachedResponse) {
+	if len(entry.body) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		c.bytes -= len(element.Value.(*cachedResponse).body)
+		c.lru.Remove(element)
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.bytes += len(entry.body)
+	for c.bytes > c.maxBytes {
+		oldest := c.lru.Back()
+		evicted := c.lru.Remove(oldest).(*cachedResponse)
+		delete(c.entries, evicted.key)
+		c.bytes -= len(evicted.body)
+	}
+}
+
+// cacheTransport makes GET requests conditional on the ETag of the response previously received for the same
+// request, and serves the cached response if the provider replies 304 Not Modified. Conditional requests answered
+// with 304 don't count against the rate limit of GitHub.
+type cacheTransport struct {
+	cache *responseCache
+	next  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.next.RoundTrip(req)
+	}
+	key := responseCacheKey(req)
+	cached := t.cache.get(key)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		header := cached.header.Clone()
+		// Headers of the 304 response, such as the rate limit, are more recent than the cached ones.
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		resp.StatusCode = cached.status
+		resp.Status = http.StatusText(cached.status)
+		resp.Header = header
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.cache.add(&cachedResponse{
+		key:    key,
+		etag:   etag,
+		status: resp.StatusCode,
+		header: resp.Header.Clone(),
+		body:   body,
+	})
+	return resp, nil
+}
+
+// responseCacheKey identifies a request by its URL and the headers that may change the response. The credentials
+// are part of the key, so that responses are never served to a client using different credentials; they are hashed
+// to avoid keeping them in memory.
+func responseCacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, name := range []string{"Authorization", "Accept"} {
+		hash.Write([]byte(name + ":" + strings.Join(req.Header.Values(name), ",") + "\n"))
+	}
+	return req.URL.String() + " " + hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,65 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheTransport(t *testing.T) {
+	requests, notModified := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := `"` + r.Header.Get("Authorization") + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprintf(w, "response for %s", r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &cacheTransport{cache: newResponseCache(1 << 20), next: http.DefaultTransport}}
+	get := func(authorization string) string {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", authorization)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "response for token a", get("token a"))
+	assert.Equal(t, "response for token a", get("token a"))
+	// Responses are not shared between credentials.
+	assert.Equal(t, "response for token b", get("token b"))
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 1, notModified)
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	cache := newResponseCache(10)
+	cache.add(&cachedResponse{key: "a", body: []byte("12345")})
+	cache.add(&cachedResponse{key: "b", body: []byte("12345")})
+	// Using a makes b the least recently used entry.
+	assert.NotNil(t, cache.get("a"))
+	cache.add(&cachedResponse{key: "c", body: []byte("12345")})
+	assert.NotNil(t, cache.get("a"))
+	assert.Nil(t, cache.get("b"))
+	assert.NotNil(t, cache.get("c"))
+	// Entries larger than the cache are not kept.
+	cache.add(&cachedResponse{key: "d", body: []byte("12345678901")})
+	assert.Nil(t, cache.get("d"))
+	assert.NotNil(t, cache.get("c"))
+}
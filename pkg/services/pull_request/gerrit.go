@@ -0,0 +1,135 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj/applicationset/pkg/services/gerrit"
+)
+
+// gerritTimeLayout is the timestamp format used by the Gerrit REST API. Timestamps are always in UTC.
+const gerritTimeLayout = "2006-01-02 15:04:05.000000000"
+
+type GerritService struct {
+	client  *gerrit.Client
+	project string
+}
+
+var _ PullRequestService = (*GerritService)(nil)
+
+// gerritChange is the subset of the Gerrit ChangeInfo entity used by the service.
+type gerritChange struct {
+	Number          int                           `json:"_number"`
+	Project         string                        `json:"project"`
+	Branch          string                        `json:"branch"`
+	Subject         string                        `json:"subject"`
+	Created         string                        `json:"created"`
+	Updated         string                        `json:"updated"`
+	Owner           gerritAccount                 `json:"owner"`
+	CurrentRevision string                        `json:"current_revision"`
+	Revisions       map[string]gerritRevisionInfo `json:"revisions"`
+	MoreChanges     bool                          `json:"_more_changes"`
+}
+
+type gerritAccount struct {
+	Username string `json:"username"`
+}
+
+type gerritRevisionInfo struct {
+	Ref string `json:"ref"`
+}
+
+// NewGerritService returns a service listing the open changes of a Gerrit project. Each change is treated as a pull
+// request whose branch is the ref of its current patch set. If username is empty, requests are made anonymously;
+// otherwise password must be the user's Gerrit HTTP password.
+func NewGerritService(ctx context.Context, username, password, url, project, proxy string) (PullRequestService, error) {
+	if url == "" {
+		return nil, fmt.Errorf("gerrit API URL is required")
+	}
+	if project == "" {
+		return nil, fmt.Errorf("gerrit project is required")
+	}
+	client, err := newHTTPClient("gerrit", proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &GerritService{
+		client:  gerrit.NewClient(client, url, username, password),
+		project: project,
+	}, nil
+}
+
+func (g *GerritService) List(ctx context.Context) ([]*PullRequest, error) {
+	pullRequests := []*PullRequest{}
+	start := 0
+	for {
+		changes, err := g.listChanges(ctx, start)
+		if err != nil {
+			return nil, fmt.Errorf("error listing changes for %s: %w", g.project, err)
+		}
+		for _, change := range changes {
+			revision, ok := change.Revisions[change.CurrentRevision]
+			if !ok {
+				return nil, fmt.Errorf("change %d of %s has no current revision", change.Number, g.project)
+			}
+			createdAt, err := parseGerritTime(change.Created)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing creation time of change %d: %v", change.Number, err)
+			}
+			updatedAt, err := parseGerritTime(change.Updated)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing update time of change %d: %v", change.Number, err)
+			}
+			pullRequests = append(pullRequests, &PullRequest{
+				Number:       change.Number,
+				Branch:       revision.Ref,
+				HeadSHA:      change.CurrentRevision,
+				Title:        change.Subject,
+				Author:       change.Owner.Username,
+				URL:          fmt.Sprintf("%s/c/%s/+/%d", g.client.URL(), change.Project, change.Number),
+				TargetBranch: change.Branch,
+				CreatedAt:    createdAt,
+				UpdatedAt:    updatedAt,
+			})
+		}
+		// Gerrit flags the last change of a page when more results are available.
+		if len(changes) == 0 || !changes[len(changes)-1].MoreChanges {
+			break
+		}
+		start += len(changes)
+	}
+	return pullRequests, nil
+}
+
+// listChanges fetches one page of open changes of the project, starting at the given offset.
+func (g *GerritService) listChanges(ctx context.Context, start int) ([]gerritChange, error) {
+	query := url.Values{}
+	query.Set("q", "status:open project:"+gerritQuote(g.project))
+	query.Add("o", "CURRENT_REVISION")
+	query.Add("o", "DETAILED_ACCOUNTS")
+	query.Set("n", "100")
+	query.Set("S", strconv.Itoa(start))
+
+	var changes []gerritChange
+	if err := g.client.Get(withEndpoint(ctx, "list_changes"), "/changes/", query, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// gerritQuote quotes a value of a search operator, so that spaces and operators in it are not parsed as part of the
+// query.
+func gerritQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func parseGerritTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(gerritTimeLayout, value, time.UTC)
+}
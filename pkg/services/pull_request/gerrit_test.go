@@ -0,0 +1,142 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gerritMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if r.URL.Path != "/a/changes/" || !ok || username != "jdoe" || password != "http-password" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, `status:open project:"platform/web"`, r.URL.Query().Get("q"))
+		assert.ElementsMatch(t, []string{"CURRENT_REVISION", "DETAILED_ACCOUNTS"}, r.URL.Query()["o"])
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("S") {
+		case "0":
+			fmt.Fprint(w, `)]}'
+[
+	{
+		"_number": 12345,
+		"project": "platform/web",
+		"branch": "main",
+		"subject": "Add feature",
+		"created": "2021-11-02 10:00:00.000000000",
+		"updated": "2021-11-03 12:30:00.000000000",
+		"owner": {"_account_id": 1000, "username": "jdoe"},
+		"current_revision": "089d92cbf9ff857a39e6feccd32798ca700fb958",
+		"revisions": {
+			"089d92cbf9ff857a39e6feccd32798ca700fb958": {"_number": 3, "ref": "refs/changes/45/12345/3"}
+		},
+		"_more_changes": true
+	}
+]`)
+		case "1":
+			fmt.Fprint(w, `)]}'
+[
+	{
+		"_number": 12346,
+		"project": "platform/web",
+		"branch": "release-1.0",
+		"subject": "Fix bug",
+		"created": "2021-11-04 08:15:00.000000000",
+		"updated": "2021-11-04 08:15:00.000000000",
+		"owner": {"_account_id": 1001, "username": "asmith"},
+		"current_revision": "7d2b1a9b4f3e3c2b1a0f9e8d7c6b5a4f3e2d1c0b",
+		"revisions": {
+			"7d2b1a9b4f3e3c2b1a0f9e8d7c6b5a4f3e2d1c0b": {"_number": 1, "ref": "refs/changes/46/12346/1"}
+		}
+	}
+]`)
+		default:
+			t.Errorf("unexpected start offset: %s", r.URL.Query().Get("S"))
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGerritList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gerritMockHandler(t)))
+	defer ts.Close()
+
+	svc, err := NewGerritService(context.Background(), "jdoe", "http-password", ts.URL+"/", "platform/web", "")
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{
+		{
+			Number:       12345,
+			Branch:       "refs/changes/45/12345/3",
+			HeadSHA:      "089d92cbf9ff857a39e6feccd32798ca700fb958",
+			Title:        "Add feature",
+			Author:       "jdoe",
+			URL:          ts.URL + "/c/platform/web/+/12345",
+			TargetBranch: "main",
+			CreatedAt:    time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2021, 11, 3, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			Number:       12346,
+			Branch:       "refs/changes/46/12346/1",
+			HeadSHA:      "7d2b1a9b4f3e3c2b1a0f9e8d7c6b5a4f3e2d1c0b",
+			Title:        "Fix bug",
+			Author:       "asmith",
+			URL:          ts.URL + "/c/platform/web/+/12346",
+			TargetBranch: "release-1.0",
+			CreatedAt:    time.Date(2021, 11, 4, 8, 15, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2021, 11, 4, 8, 15, 0, 0, time.UTC),
+		},
+	}, pullRequests)
+}
+
+func TestGerritListAnonymous(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.False(t, ok)
+		assert.Equal(t, "/changes/", r.URL.Path)
+		fmt.Fprint(w, ")]}'\n[]")
+	}))
+	defer ts.Close()
+
+	svc, err := NewGerritService(context.Background(), "", "", ts.URL, "platform/web", "")
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, pullRequests)
+}
+
+func TestGerritListError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "Not found: platform/web")
+	}))
+	defer ts.Close()
+
+	svc, err := NewGerritService(context.Background(), "", "", ts.URL, "platform/web", "")
+	assert.NoError(t, err)
+	_, err = svc.List(context.Background())
+	assert.EqualError(t, err, "error listing changes for platform/web: unexpected status 404: Not found: platform/web")
+}
+
+func TestNewGerritServiceValidation(t *testing.T) {
+	_, err := NewGerritService(context.Background(), "", "", "", "platform/web", "")
+	assert.Error(t, err)
+	_, err = NewGerritService(context.Background(), "", "", "https://review.example.com", "", "")
+	assert.EqualError(t, err, "gerrit project is required")
+}
+
+func TestGerritQuote(t *testing.T) {
+	assert.Equal(t, `"platform/web"`, gerritQuote("platform/web"))
+	assert.Equal(t, `"my project OR is:open"`, gerritQuote("my project OR is:open"))
+	assert.Equal(t, `"say \"hi\" \\o/"`, gerritQuote(`say "hi" \o/`))
+}
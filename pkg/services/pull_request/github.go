@@ -1,24 +1,49 @@
 package pull_request
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
 
+	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v35/github"
 	"golang.org/x/oauth2"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
 )
 
 type GithubService struct {
-	client *github.Client
-	owner  string
-	repo   string
-	labels []string
+	client        *github.Client
+	owner         string
+	repo          string
+	labels        []string
+	excludeLabels []string
+	skipDraft     bool
+	// baseBranchMatch is nil if pull requests are not filtered on their base branch.
+	baseBranchMatch *regexp.Regexp
+	// authorMatch is nil if pull requests are not filtered on the login of their author.
+	authorMatch *regexp.Regexp
+	// authorTeams are slugs of teams of the owner organization, one of which the author must be an active member of.
+	authorTeams []string
+	// milestone is the title of the milestone pull requests must be assigned to, if not empty.
+	milestone string
+	// skipUnmergeable excludes pull requests GitHub reports as conflicting or blocked.
+	skipUnmergeable bool
+	// checks is nil if pull requests are not filtered on CI results.
+	checks *githubChecks
 }
 
 var _ PullRequestService = (*GithubService)(nil)
 
-func NewGithubService(ctx context.Context, token, url, owner, repo string, labels []string) (PullRequestService, error) {
+// NewGithubService returns a service listing the open pull requests of the repository described by config, which
+// also holds the filters to apply. token is used to authenticate if not empty.
+func NewGithubService(ctx context.Context, token string, config *argoprojiov1alpha1.PullRequestGeneratorGithub) (PullRequestService, error) {
 	var ts oauth2.TokenSource
 	// Undocumented environment variable to set a default token, to be used in testing to dodge anonymous rate limits.
 	if token == "" {
@@ -29,22 +54,129 @@ func NewGithubService(ctx context.Context, token, url, owner, repo string, label
 			&oauth2.Token{AccessToken: token},
 		)
 	}
-	httpClient := oauth2.NewClient(ctx, ts)
+	baseClient, err := newHTTPClient("github", config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, baseClient), ts)
+	return newGithubService(httpClient, config)
+}
+
+// NewGithubAppService is like NewGithubService, but authenticates as the installation of the GitHub App in
+// config.App, using the App's PEM-encoded private key. The transport authenticating as the installation is taken
+// from transports, so that its installation token is reused across reconciles until it expires.
+func NewGithubAppService(ctx context.Context, transports *GithubAppTransports, privateKey []byte, config *argoprojiov1alpha1.PullRequestGeneratorGithub) (PullRequestService, error) {
+	transport, err := transports.get(privateKey, config)
+	if err != nil {
+		return nil, err
+	}
+	return newGithubService(&http.Client{Transport: transport}, config)
+}
+
+// GithubAppTransports caches the transports authenticating as GitHub App installations, by API URL, App and
+// installation. A nil *GithubAppTransports creates a new transport every time.
+type GithubAppTransports struct {
+	mu         sync.Mutex
+	transports map[githubAppKey]*githubAppTransport
+}
+
+type githubAppKey struct {
+	api            string
+	appID          int64
+	installationID int64
+}
+
+// githubAppTransport is a cached transport, along with the private key and proxy it was created with. It is
+// replaced when either changes.
+type githubAppTransport struct {
+	privateKey []byte
+	proxy      string
+	transport  *ghinstallation.Transport
+}
+
+// NewGithubAppTransports returns an empty cache of GitHub App transports.
+func NewGithubAppTransports() *GithubAppTransports {
+	return &GithubAppTransports{transports: map[githubAppKey]*githubAppTransport{}}
+}
+
+func (c *GithubAppTransports) get(privateKey []byte, config *argoprojiov1alpha1.PullRequestGeneratorGithub) (*ghinstallation.Transport, error) {
+	if c == nil {
+		return newGithubAppTransport(privateKey, config)
+	}
+	key := githubAppKey{api: config.API, appID: config.App.AppID, installationID: config.App.InstallationID}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.transports[key]; ok && bytes.Equal(cached.privateKey, privateKey) && cached.proxy == config.Proxy {
+		return cached.transport, nil
+	}
+	transport, err := newGithubAppTransport(privateKey, config)
+	if err != nil {
+		return nil, err
+	}
+	c.transports[key] = &githubAppTransport{privateKey: privateKey, proxy: config.Proxy, transport: transport}
+	return transport, nil
+}
+
+func newGithubAppTransport(privateKey []byte, config *argoprojiov1alpha1.PullRequestGeneratorGithub) (*ghinstallation.Transport, error) {
+	baseClient, err := newHTTPClient("github", config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := ghinstallation.New(baseClient.Transport, config.App.AppID, config.App.InstallationID, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub App transport: %v", err)
+	}
+	if config.API != "" {
+		// Unlike go-github, ghinstallation expects the full API root of GitHub Enterprise.
+		transport.BaseURL = strings.TrimSuffix(config.API, "/")
+		if !strings.HasSuffix(transport.BaseURL, "/api/v3") {
+			transport.BaseURL += "/api/v3"
+		}
+	}
+	return transport, nil
+}
+
+func newGithubService(httpClient *http.Client, config *argoprojiov1alpha1.PullRequestGeneratorGithub) (PullRequestService, error) {
+	checks, err := compileGithubChecks(config.Checks)
+	if err != nil {
+		return nil, err
+	}
+	var baseBranchMatch *regexp.Regexp
+	if config.BaseBranchMatch != nil {
+		baseBranchMatch, err = regexp.Compile(*config.BaseBranchMatch)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling BaseBranchMatch regexp %q: %v", *config.BaseBranchMatch, err)
+		}
+	}
+	var authorMatch *regexp.Regexp
+	if config.AuthorMatch != nil {
+		authorMatch, err = regexp.Compile(*config.AuthorMatch)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling AuthorMatch regexp %q: %v", *config.AuthorMatch, err)
+		}
+	}
 	var client *github.Client
-	if url == "" {
+	if config.API == "" {
 		client = github.NewClient(httpClient)
 	} else {
-		var err error
-		client, err = github.NewEnterpriseClient(url, url, httpClient)
+		client, err = github.NewEnterpriseClient(config.API, config.API, httpClient)
 		if err != nil {
 			return nil, err
 		}
 	}
 	return &GithubService{
-		client: client,
-		owner:  owner,
-		repo:   repo,
-		labels: labels,
+		client:          client,
+		owner:           config.Owner,
+		repo:            config.Repo,
+		labels:          config.Labels,
+		excludeLabels:   config.ExcludeLabels,
+		skipDraft:       config.SkipDraft,
+		baseBranchMatch: baseBranchMatch,
+		authorMatch:     authorMatch,
+		authorTeams:     config.AuthorTeams,
+		milestone:       config.Milestone,
+		skipUnmergeable: config.SkipUnmergeable,
+		checks:          checks,
 	}, nil
 }
 
@@ -55,19 +187,76 @@ func (g *GithubService) List(ctx context.Context) ([]*PullRequest, error) {
 		},
 	}
 	pullRequests := []*PullRequest{}
+	// Team membership of each author is looked up at most once per List.
+	teamMembers := map[string]bool{}
 	for {
-		pulls, resp, err := g.client.PullRequests.List(ctx, g.owner, g.repo, opts)
+		pulls, resp, err := g.client.PullRequests.List(withEndpoint(ctx, "list_pull_requests"), g.owner, g.repo, opts)
 		if err != nil {
-			return nil, fmt.Errorf("error listing pull requests for %s/%s: %v", g.owner, g.repo, err)
+			return nil, fmt.Errorf("error listing pull requests for %s/%s: %w", g.owner, g.repo, ratelimit.FromGithub(err))
 		}
 		for _, pull := range pulls {
 			if !containLabels(g.labels, pull.Labels) {
 				continue
 			}
+			if containAnyLabel(g.excludeLabels, pull.Labels) {
+				continue
+			}
+			if g.skipDraft && pull.GetDraft() {
+				continue
+			}
+			if g.baseBranchMatch != nil && !g.baseBranchMatch.MatchString(pull.GetBase().GetRef()) {
+				continue
+			}
+			if g.milestone != "" && pull.GetMilestone().GetTitle() != g.milestone {
+				continue
+			}
+			author := pull.GetUser().GetLogin()
+			if g.authorMatch != nil && !g.authorMatch.MatchString(author) {
+				continue
+			}
+			if len(g.authorTeams) > 0 {
+				member, ok := teamMembers[author]
+				if !ok {
+					member, err = g.isTeamMember(ctx, author)
+					if err != nil {
+						return nil, err
+					}
+					teamMembers[author] = member
+				}
+				if !member {
+					continue
+				}
+			}
+			if g.skipUnmergeable {
+				mergeable, err := g.isMergeable(ctx, *pull.Number)
+				if err != nil {
+					return nil, err
+				}
+				if !mergeable {
+					continue
+				}
+			}
+			headSHA := *pull.Head.SHA
+			if g.checks != nil {
+				headSHA, err = g.findGreenCommit(ctx, *pull.Number, headSHA)
+				if err != nil {
+					return nil, err
+				}
+				if headSHA == "" {
+					continue
+				}
+			}
 			pullRequests = append(pullRequests, &PullRequest{
-				Number:  *pull.Number,
-				Branch:  *pull.Head.Ref,
-				HeadSHA: *pull.Head.SHA,
+				Number:       *pull.Number,
+				Branch:       *pull.Head.Ref,
+				HeadSHA:      headSHA,
+				Title:        pull.GetTitle(),
+				Author:       author,
+				URL:          pull.GetHTMLURL(),
+				TargetBranch: pull.GetBase().GetRef(),
+				CreatedAt:    pull.GetCreatedAt(),
+				UpdatedAt:    pull.GetUpdatedAt(),
+				Labels:       labelNames(pull.Labels),
 			})
 		}
 		if resp.NextPage == 0 {
@@ -78,6 +267,49 @@ func (g *GithubService) List(ctx context.Context) ([]*PullRequest, error) {
 	return pullRequests, nil
 }
 
+// isTeamMember returns true if user is an active member of at least one of the author teams.
+func (g *GithubService) isTeamMember(ctx context.Context, user string) (bool, error) {
+	for _, team := range g.authorTeams {
+		membership, resp, err := g.client.Teams.GetTeamMembershipBySlug(withEndpoint(ctx, "get_team_membership"), g.owner, team, user)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("error getting membership of %s in team %s/%s: %w", user, g.owner, team, ratelimit.FromGithub(err))
+		}
+		if membership.GetState() == "active" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isMergeable returns false if the pull request has merge conflicts or is blocked from merging, eg by failing
+// required checks or missing reviews. The mergeable state is only returned when getting a single pull request, and
+// is computed asynchronously by GitHub: pull requests whose state is not known yet are considered mergeable.
+func (g *GithubService) isMergeable(ctx context.Context, number int) (bool, error) {
+	pull, _, err := g.client.PullRequests.Get(withEndpoint(ctx, "get_pull_request"), g.owner, g.repo, number)
+	if err != nil {
+		return false, fmt.Errorf("error getting pull request %s/%s#%d: %w", g.owner, g.repo, number, ratelimit.FromGithub(err))
+	}
+	switch pull.GetMergeableState() {
+	case "dirty", "blocked":
+		return false, nil
+	}
+	return true, nil
+}
+
+// labelNames returns the names of labels
+func labelNames(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label.Name != nil {
+			names = append(names, *label.Name)
+		}
+	}
+	return names
+}
+
 // containLabels returns true if gotLabels contains expectedLabels
 func containLabels(expectedLabels []string, gotLabels []*github.Label) bool {
 	for _, expected := range expectedLabels {
@@ -97,3 +329,15 @@ func containLabels(expectedLabels []string, gotLabels []*github.Label) bool {
 	}
 	return true
 }
+
+// containAnyLabel returns true if gotLabels contains at least one of labels
+func containAnyLabel(labels []string, gotLabels []*github.Label) bool {
+	for _, label := range labels {
+		for _, got := range gotLabels {
+			if got.Name != nil && label == *got.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
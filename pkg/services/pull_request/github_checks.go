@@ -0,0 +1,203 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-github/v35/github"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+)
+
+// githubChecks holds the compiled CI requirements a pull request's head commit must meet to be emitted.
+type githubChecks struct {
+	// required is a list of patterns, each of which must match at least one check, with all matching checks
+	// successful. If empty, every check must be successful.
+	required []*regexp.Regexp
+	// findLatestSuccessful falls back to the newest earlier commit of the pull request that meets the requirements
+	// if the head commit doesn't.
+	findLatestSuccessful bool
+}
+
+// checkResult is the outcome of a single check run or commit status context.
+type checkResult struct {
+	name       string
+	successful bool
+}
+
+func compileGithubChecks(checks *argoprojiov1alpha1.PullRequestGeneratorGithubChecks) (*githubChecks, error) {
+	if checks == nil {
+		return nil, nil
+	}
+	compiled := &githubChecks{
+		findLatestSuccessful: checks.FindLatestSuccessful,
+	}
+	for _, required := range checks.Required {
+		re, err := regexp.Compile(required)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling required check regexp %q: %v", required, err)
+		}
+		compiled.required = append(compiled.required, re)
+	}
+	return compiled, nil
+}
+
+// isCommitGreen returns true if the check runs and commit statuses reported for the commit satisfy the configured
+// requirements.
+func (g *GithubService) isCommitGreen(ctx context.Context, sha string) (bool, error) {
+	results, err := g.getCheckResults(ctx, sha)
+	if err != nil {
+		return false, err
+	}
+	return g.checks.isGreen(results), nil
+}
+
+// findGreenCommit returns the SHA of the commit of the pull request that should be targeted: the head commit if it
+// meets the CI requirements, otherwise, if findLatestSuccessful is set, the newest earlier commit that does. An
+// empty SHA is returned if there is no such commit.
+func (g *GithubService) findGreenCommit(ctx context.Context, number int, headSHA string) (string, error) {
+	green, err := g.isCommitGreen(ctx, headSHA)
+	if err != nil {
+		return "", err
+	}
+	if green {
+		return headSHA, nil
+	}
+	if !g.checks.findLatestSuccessful {
+		return "", nil
+	}
+
+	commits, err := g.getPullRequestCommits(ctx, number)
+	if err != nil {
+		return "", err
+	}
+	// Commits are listed oldest first, so walk backwards from the commit preceding the head.
+	for i := len(commits) - 1; i >= 0; i-- {
+		sha := commits[i].GetSHA()
+		if sha == headSHA {
+			continue
+		}
+		green, err := g.isCommitGreen(ctx, sha)
+		if err != nil {
+			return "", err
+		}
+		if green {
+			return sha, nil
+		}
+	}
+	return "", nil
+}
+
+// getPullRequestCommits gets the commits of the pull request, oldest first.
+func (g *GithubService) getPullRequestCommits(ctx context.Context, number int) ([]*github.RepositoryCommit, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+	commits := []*github.RepositoryCommit{}
+	for {
+		page, resp, err := g.client.PullRequests.ListCommits(withEndpoint(ctx, "list_pull_request_commits"), g.owner, g.repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing commits of pull request %s/%s#%d: %w", g.owner, g.repo, number, err)
+		}
+		commits = append(commits, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return commits, nil
+}
+
+func (c *githubChecks) isGreen(results []checkResult) bool {
+	if len(c.required) == 0 {
+		// Nothing reported yet most likely means CI hasn't started, rather than that there is nothing to wait for.
+		if len(results) == 0 {
+			return false
+		}
+		for _, result := range results {
+			if !result.successful {
+				return false
+			}
+		}
+		return true
+	}
+	for _, required := range c.required {
+		matched := false
+		for _, result := range results {
+			if !required.MatchString(result.name) {
+				continue
+			}
+			if !result.successful {
+				return false
+			}
+			matched = true
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// getCheckResults gets the latest result of every check run and commit status context reported for the commit.
+func (g *GithubService) getCheckResults(ctx context.Context, sha string) ([]checkResult, error) {
+	results := []checkResult{}
+
+	checkRunOpts := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+	for {
+		checkRuns, resp, err := g.client.Checks.ListCheckRunsForRef(withEndpoint(ctx, "list_check_runs"), g.owner, g.repo, sha, checkRunOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error listing check runs for %s/%s@%s: %w", g.owner, g.repo, sha, err)
+		}
+		for _, checkRun := range checkRuns.CheckRuns {
+			results = append(results, checkResult{
+				name:       checkRun.GetName(),
+				successful: isCheckRunSuccessful(checkRun),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		checkRunOpts.Page = resp.NextPage
+	}
+
+	statusOpts := &github.ListOptions{
+		PerPage: 100,
+	}
+	for {
+		combined, resp, err := g.client.Repositories.GetCombinedStatus(withEndpoint(ctx, "get_combined_status"), g.owner, g.repo, sha, statusOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error getting commit status for %s/%s@%s: %w", g.owner, g.repo, sha, err)
+		}
+		for _, status := range combined.Statuses {
+			results = append(results, checkResult{
+				name:       status.GetContext(),
+				successful: status.GetState() == "success",
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		statusOpts.Page = resp.NextPage
+	}
+
+	return results, nil
+}
+
+// isCheckRunSuccessful returns true if the check run completed with a conclusion that doesn't block merging,
+// consistent with how GitHub evaluates required status checks.
+func isCheckRunSuccessful(checkRun *github.CheckRun) bool {
+	if checkRun.GetStatus() != "completed" {
+		return false
+	}
+	switch checkRun.GetConclusion() {
+	case "success", "neutral", "skipped":
+		return true
+	}
+	return false
+}
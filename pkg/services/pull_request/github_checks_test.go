@@ -0,0 +1,205 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+)
+
+func TestGithubChecksIsGreen(t *testing.T) {
+	cases := []struct {
+		name     string
+		required []string
+		results  []checkResult
+		expected bool
+	}{
+		{
+			name:     "all successful",
+			results:  []checkResult{{name: "build", successful: true}, {name: "ci/jenkins", successful: true}},
+			expected: true,
+		},
+		{
+			name:     "one failed",
+			results:  []checkResult{{name: "build", successful: true}, {name: "ci/jenkins", successful: false}},
+			expected: false,
+		},
+		{
+			name:     "nothing reported",
+			results:  []checkResult{},
+			expected: false,
+		},
+		{
+			name:     "required successful, other failed",
+			required: []string{"^build$"},
+			results:  []checkResult{{name: "build", successful: true}, {name: "lint", successful: false}},
+			expected: true,
+		},
+		{
+			name:     "required failed",
+			required: []string{"^build$", "^lint$"},
+			results:  []checkResult{{name: "build", successful: true}, {name: "lint", successful: false}},
+			expected: false,
+		},
+		{
+			name:     "required missing",
+			required: []string{"^build$", "^e2e"},
+			results:  []checkResult{{name: "build", successful: true}},
+			expected: false,
+		},
+		{
+			name:     "pattern matches several checks",
+			required: []string{"^e2e"},
+			results:  []checkResult{{name: "e2e-aws", successful: true}, {name: "e2e-gcp", successful: false}},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checks, err := compileGithubChecks(&argoprojiov1alpha1.PullRequestGeneratorGithubChecks{Required: c.required})
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, checks.isGreen(c.results))
+		})
+	}
+}
+
+func TestCompileGithubChecks(t *testing.T) {
+	checks, err := compileGithubChecks(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, checks)
+
+	checks, err = compileGithubChecks(&argoprojiov1alpha1.PullRequestGeneratorGithubChecks{})
+	assert.NoError(t, err)
+	assert.Equal(t, &githubChecks{}, checks)
+
+	checks, err = compileGithubChecks(&argoprojiov1alpha1.PullRequestGeneratorGithubChecks{Required: []string{"^build$"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*regexp.Regexp{regexp.MustCompile("^build$")}, checks.required)
+
+	_, err = compileGithubChecks(&argoprojiov1alpha1.PullRequestGeneratorGithubChecks{Required: []string{"("}})
+	assert.Error(t, err)
+}
+
+// githubChecksMockHandler serves two open pull requests: #1 whose head commit passed every check, and #2 whose head
+// commit has a failed commit status. The first of the two earlier commits of #2 passed every check, the second has
+// no checks reported.
+func githubChecksMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/myorg/myrepo/pulls":
+			fmt.Fprint(w, `[
+				{"number": 1, "head": {"ref": "green", "sha": "1111111111111111111111111111111111111111"}},
+				{"number": 2, "head": {"ref": "red", "sha": "2222222222222222222222222222222222222222"}}
+			]`)
+		case "/api/v3/repos/myorg/myrepo/commits/1111111111111111111111111111111111111111/check-runs":
+			fmt.Fprint(w, `{"total_count": 2, "check_runs": [
+				{"name": "build", "status": "completed", "conclusion": "success"},
+				{"name": "docs", "status": "completed", "conclusion": "skipped"}
+			]}`)
+		case "/api/v3/repos/myorg/myrepo/commits/1111111111111111111111111111111111111111/status":
+			fmt.Fprint(w, `{"state": "success", "statuses": [{"context": "ci/jenkins", "state": "success"}]}`)
+		case "/api/v3/repos/myorg/myrepo/commits/2222222222222222222222222222222222222222/check-runs":
+			fmt.Fprint(w, `{"total_count": 1, "check_runs": [
+				{"name": "build", "status": "completed", "conclusion": "success"}
+			]}`)
+		case "/api/v3/repos/myorg/myrepo/commits/2222222222222222222222222222222222222222/status":
+			fmt.Fprint(w, `{"state": "failure", "statuses": [{"context": "ci/jenkins", "state": "failure"}]}`)
+		case "/api/v3/repos/myorg/myrepo/pulls/2/commits":
+			fmt.Fprint(w, `[
+				{"sha": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+				{"sha": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+				{"sha": "2222222222222222222222222222222222222222"}
+			]`)
+		case "/api/v3/repos/myorg/myrepo/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/check-runs":
+			fmt.Fprint(w, `{"total_count": 1, "check_runs": [
+				{"name": "build", "status": "completed", "conclusion": "success"}
+			]}`)
+		case "/api/v3/repos/myorg/myrepo/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/status":
+			fmt.Fprint(w, `{"state": "success", "statuses": [{"context": "ci/jenkins", "state": "success"}]}`)
+		case "/api/v3/repos/myorg/myrepo/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/check-runs":
+			fmt.Fprint(w, `{"total_count": 0, "check_runs": []}`)
+		case "/api/v3/repos/myorg/myrepo/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/status":
+			fmt.Fprint(w, `{"state": "pending", "statuses": []}`)
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGithubListWithChecks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(githubChecksMockHandler(t)))
+	defer ts.Close()
+
+	cases := []struct {
+		name     string
+		checks   *argoprojiov1alpha1.PullRequestGeneratorGithubChecks
+		expected map[int]string
+	}{
+		{
+			name:   "checks not configured",
+			checks: nil,
+			expected: map[int]string{
+				1: "1111111111111111111111111111111111111111",
+				2: "2222222222222222222222222222222222222222",
+			},
+		},
+		{
+			name:   "all checks required",
+			checks: &argoprojiov1alpha1.PullRequestGeneratorGithubChecks{},
+			expected: map[int]string{
+				1: "1111111111111111111111111111111111111111",
+			},
+		},
+		{
+			name:   "only build required",
+			checks: &argoprojiov1alpha1.PullRequestGeneratorGithubChecks{Required: []string{"^build$"}},
+			expected: map[int]string{
+				1: "1111111111111111111111111111111111111111",
+				2: "2222222222222222222222222222222222222222",
+			},
+		},
+		{
+			name:   "jenkins status required",
+			checks: &argoprojiov1alpha1.PullRequestGeneratorGithubChecks{Required: []string{"^ci/jenkins$"}},
+			expected: map[int]string{
+				1: "1111111111111111111111111111111111111111",
+			},
+		},
+		{
+			name:   "find latest successful",
+			checks: &argoprojiov1alpha1.PullRequestGeneratorGithubChecks{FindLatestSuccessful: true},
+			expected: map[int]string{
+				1: "1111111111111111111111111111111111111111",
+				2: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+				API:    ts.URL,
+				Owner:  "myorg",
+				Repo:   "myrepo",
+				Checks: c.checks,
+			})
+			assert.NoError(t, err)
+			pullRequests, err := svc.List(context.Background())
+			assert.NoError(t, err)
+			heads := map[int]string{}
+			for _, pull := range pullRequests {
+				heads[pull.Number] = pull.HeadSHA
+			}
+			assert.Equal(t, c.expected, heads)
+		})
+	}
+}
@@ -1,9 +1,22 @@
 package pull_request
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
 )
 
 func toPtr(s string) *string {
@@ -57,3 +70,412 @@ func TestContainLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestContainAnyLabel(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Labels     []string
+		PullLabels []*github.Label
+		Expect     bool
+	}{
+		{
+			Name:   "Match one label",
+			Labels: []string{"no-deploy", "label4"},
+			PullLabels: []*github.Label{
+				&github.Label{Name: toPtr("label1")},
+				&github.Label{Name: toPtr("no-deploy")},
+			},
+			Expect: true,
+		},
+		{
+			Name:   "Match no label",
+			Labels: []string{"no-deploy"},
+			PullLabels: []*github.Label{
+				&github.Label{Name: toPtr("label1")},
+				&github.Label{Name: toPtr("label2")},
+			},
+			Expect: false,
+		},
+		{
+			Name:   "No specify",
+			Labels: []string{},
+			PullLabels: []*github.Label{
+				&github.Label{Name: toPtr("label1")},
+			},
+			Expect: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if got := containAnyLabel(c.Labels, c.PullLabels); got != c.Expect {
+				t.Errorf("expect: %v, got: %v", c.Expect, got)
+			}
+		})
+	}
+}
+
+func TestGithubListExcludeLabels(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "labels": [{"name": "preview"}], "head": {"ref": "a", "sha": "1111111111111111111111111111111111111111"}},
+			{"number": 2, "labels": [{"name": "preview"}, {"name": "no-deploy"}], "head": {"ref": "b", "sha": "2222222222222222222222222222222222222222"}},
+			{"number": 3, "labels": [], "head": {"ref": "c", "sha": "3333333333333333333333333333333333333333"}}
+		]`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:           ts.URL,
+		Owner:         "myorg",
+		Repo:          "myrepo",
+		Labels:        []string{"preview"},
+		ExcludeLabels: []string{"no-deploy"},
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, pullRequests, 1)
+	assert.Equal(t, 1, pullRequests[0].Number)
+}
+
+func TestGithubList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/myorg/myrepo/pulls" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{
+				"number": 101,
+				"html_url": "https://github.com/myorg/myrepo/pull/101",
+				"title": "Add feature",
+				"created_at": "2021-11-02T10:00:00Z",
+				"updated_at": "2021-11-03T12:30:00Z",
+				"user": {"login": "octocat"},
+				"labels": [{"name": "preview"}],
+				"head": {"ref": "feature", "sha": "089d92cbf9ff857a39e6feccd32798ca700fb958"},
+				"base": {"ref": "main", "sha": "2a4ab0c6c4d6d7e2fb1c3a4b7e8e4f1e2d3c4b5a"}
+			},
+			{
+				"number": 102,
+				"title": "Unlabeled",
+				"user": {"login": "octocat"},
+				"head": {"ref": "other", "sha": "7d2b1a9b4f3e3c2b1a0f9e8d7c6b5a4f3e2d1c0b"},
+				"base": {"ref": "main", "sha": "2a4ab0c6c4d6d7e2fb1c3a4b7e8e4f1e2d3c4b5a"}
+			}
+		]`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:    ts.URL,
+		Owner:  "myorg",
+		Repo:   "myrepo",
+		Labels: []string{"preview"},
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{
+		{
+			Number:       101,
+			Branch:       "feature",
+			HeadSHA:      "089d92cbf9ff857a39e6feccd32798ca700fb958",
+			Title:        "Add feature",
+			Author:       "octocat",
+			URL:          "https://github.com/myorg/myrepo/pull/101",
+			TargetBranch: "main",
+			CreatedAt:    time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2021, 11, 3, 12, 30, 0, 0, time.UTC),
+			Labels:       []string{"preview"},
+		},
+	}, pullRequests)
+}
+
+func TestGithubListThroughProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxied plain HTTP request carries the absolute URL of the origin server.
+		assert.Equal(t, "github.example.com", r.URL.Host)
+		assert.Equal(t, "/api/v3/repos/myorg/myrepo/pulls", r.URL.Path)
+		proxied = true
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer proxy.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:   "http://github.example.com/",
+		Owner: "myorg",
+		Repo:  "myrepo",
+		Proxy: proxy.URL,
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, pullRequests)
+	assert.True(t, proxied)
+}
+
+func TestGithubListSkipDraft(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "draft": false, "head": {"ref": "ready", "sha": "1111111111111111111111111111111111111111"}},
+			{"number": 2, "draft": true, "head": {"ref": "wip", "sha": "2222222222222222222222222222222222222222"}}
+		]`)
+	}))
+	defer ts.Close()
+
+	for _, skipDraft := range []bool{false, true} {
+		t.Run(fmt.Sprintf("skipDraft=%v", skipDraft), func(t *testing.T) {
+			svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+				API:       ts.URL,
+				Owner:     "myorg",
+				Repo:      "myrepo",
+				SkipDraft: skipDraft,
+			})
+			assert.NoError(t, err)
+			pullRequests, err := svc.List(context.Background())
+			assert.NoError(t, err)
+			numbers := []int{}
+			for _, pull := range pullRequests {
+				numbers = append(numbers, pull.Number)
+			}
+			if skipDraft {
+				assert.Equal(t, []int{1}, numbers)
+			} else {
+				assert.Equal(t, []int{1, 2}, numbers)
+			}
+		})
+	}
+}
+
+func TestGithubAppList(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tokenRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/app/installations/456/access_tokens":
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "))
+			tokenRequests++
+			fmt.Fprintf(w, `{"token": "ghs_installation", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		case "/api/v3/repos/myorg/myrepo/pulls":
+			assert.Equal(t, "token ghs_installation", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `[{"number": 1, "head": {"ref": "feature", "sha": "1111111111111111111111111111111111111111"}}]`)
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:   ts.URL,
+		Owner: "myorg",
+		Repo:  "myrepo",
+		App: &argoprojiov1alpha1.PullRequestGeneratorGithubApp{
+			AppID:          123,
+			InstallationID: 456,
+		},
+	}
+	transports := NewGithubAppTransports()
+	// Services are created anew on every reconcile.
+	for i := 0; i < 2; i++ {
+		svc, err := NewGithubAppService(context.Background(), transports, privateKey, config)
+		assert.NoError(t, err)
+		pullRequests, err := svc.List(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, pullRequests, 1)
+	}
+	// The installation token is reused until it expires.
+	assert.Equal(t, 1, tokenRequests)
+
+	// A new transport is created when the private key is rotated.
+	key, err = rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	svc, err := NewGithubAppService(context.Background(), transports, privateKey, config)
+	assert.NoError(t, err)
+	_, err = svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tokenRequests)
+}
+
+func TestGithubAppInvalidKey(t *testing.T) {
+	_, err := NewGithubAppService(context.Background(), NewGithubAppTransports(), []byte("not a key"), &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		Owner: "myorg",
+		Repo:  "myrepo",
+		App:   &argoprojiov1alpha1.PullRequestGeneratorGithubApp{AppID: 123, InstallationID: 456},
+	})
+	assert.Error(t, err)
+}
+
+func TestGithubListBaseBranchMatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "head": {"ref": "a", "sha": "1111111111111111111111111111111111111111"}, "base": {"ref": "main"}},
+			{"number": 2, "head": {"ref": "b", "sha": "2222222222222222222222222222222222222222"}, "base": {"ref": "release/1.0"}},
+			{"number": 3, "head": {"ref": "c", "sha": "3333333333333333333333333333333333333333"}, "base": {"ref": "develop"}}
+		]`)
+	}))
+	defer ts.Close()
+
+	baseBranchMatch := "^(main|release/.*)$"
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:             ts.URL,
+		Owner:           "myorg",
+		Repo:            "myrepo",
+		BaseBranchMatch: &baseBranchMatch,
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	numbers := []int{}
+	for _, pull := range pullRequests {
+		numbers = append(numbers, pull.Number)
+	}
+	assert.Equal(t, []int{1, 2}, numbers)
+
+	invalid := "("
+	_, err = NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		Owner:           "myorg",
+		Repo:            "myrepo",
+		BaseBranchMatch: &invalid,
+	})
+	assert.Error(t, err)
+}
+
+func TestGithubListMilestone(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "milestone": {"title": "v1.2"}, "head": {"ref": "a", "sha": "1111111111111111111111111111111111111111"}},
+			{"number": 2, "milestone": {"title": "v1.3"}, "head": {"ref": "b", "sha": "2222222222222222222222222222222222222222"}},
+			{"number": 3, "head": {"ref": "c", "sha": "3333333333333333333333333333333333333333"}}
+		]`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:       ts.URL,
+		Owner:     "myorg",
+		Repo:      "myrepo",
+		Milestone: "v1.2",
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	numbers := []int{}
+	for _, pull := range pullRequests {
+		numbers = append(numbers, pull.Number)
+	}
+	assert.Equal(t, []int{1}, numbers)
+}
+
+func TestGithubListSkipUnmergeable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/myorg/myrepo/pulls":
+			fmt.Fprint(w, `[
+				{"number": 1, "head": {"ref": "a", "sha": "1111111111111111111111111111111111111111"}},
+				{"number": 2, "head": {"ref": "b", "sha": "2222222222222222222222222222222222222222"}},
+				{"number": 3, "head": {"ref": "c", "sha": "3333333333333333333333333333333333333333"}},
+				{"number": 4, "head": {"ref": "d", "sha": "4444444444444444444444444444444444444444"}}
+			]`)
+		case "/api/v3/repos/myorg/myrepo/pulls/1":
+			fmt.Fprint(w, `{"number": 1, "mergeable_state": "clean"}`)
+		case "/api/v3/repos/myorg/myrepo/pulls/2":
+			fmt.Fprint(w, `{"number": 2, "mergeable_state": "dirty"}`)
+		case "/api/v3/repos/myorg/myrepo/pulls/3":
+			fmt.Fprint(w, `{"number": 3, "mergeable_state": "blocked"}`)
+		case "/api/v3/repos/myorg/myrepo/pulls/4":
+			fmt.Fprint(w, `{"number": 4, "mergeable_state": "unknown"}`)
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:             ts.URL,
+		Owner:           "myorg",
+		Repo:            "myrepo",
+		SkipUnmergeable: true,
+	})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	numbers := []int{}
+	for _, pull := range pullRequests {
+		numbers = append(numbers, pull.Number)
+	}
+	assert.Equal(t, []int{1, 4}, numbers)
+}
+
+func TestGithubListAuthorFilters(t *testing.T) {
+	membershipRequests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/myorg/myrepo/pulls":
+			fmt.Fprint(w, `[
+				{"number": 1, "user": {"login": "alice"}, "head": {"ref": "a", "sha": "1111111111111111111111111111111111111111"}},
+				{"number": 2, "user": {"login": "bob"}, "head": {"ref": "b", "sha": "2222222222222222222222222222222222222222"}},
+				{"number": 3, "user": {"login": "renovate[bot]"}, "head": {"ref": "c", "sha": "3333333333333333333333333333333333333333"}},
+				{"number": 4, "user": {"login": "alice"}, "head": {"ref": "d", "sha": "4444444444444444444444444444444444444444"}},
+				{"number": 5, "user": {"login": "carol"}, "head": {"ref": "e", "sha": "5555555555555555555555555555555555555555"}}
+			]`)
+		case "/api/v3/orgs/myorg/teams/platform/memberships/alice":
+			membershipRequests["alice"]++
+			fmt.Fprint(w, `{"state": "active", "role": "member"}`)
+		case "/api/v3/orgs/myorg/teams/platform/memberships/bob":
+			membershipRequests["bob"]++
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case "/api/v3/orgs/myorg/teams/platform/memberships/carol":
+			membershipRequests["carol"]++
+			fmt.Fprint(w, `{"state": "pending", "role": "member"}`)
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	list := func(config *argoprojiov1alpha1.PullRequestGeneratorGithub) []int {
+		config.API = ts.URL
+		config.Owner = "myorg"
+		config.Repo = "myrepo"
+		svc, err := NewGithubService(context.Background(), "", config)
+		assert.NoError(t, err)
+		pullRequests, err := svc.List(context.Background())
+		assert.NoError(t, err)
+		numbers := []int{}
+		for _, pull := range pullRequests {
+			numbers = append(numbers, pull.Number)
+		}
+		return numbers
+	}
+
+	notBot := `^[^\[]+$`
+	assert.Equal(t, []int{1, 2, 4, 5}, list(&argoprojiov1alpha1.PullRequestGeneratorGithub{AuthorMatch: &notBot}))
+
+	onlyBot := `\[bot\]$`
+	assert.Equal(t, []int{3}, list(&argoprojiov1alpha1.PullRequestGeneratorGithub{AuthorMatch: &onlyBot}))
+
+	assert.Equal(t, []int{1, 4}, list(&argoprojiov1alpha1.PullRequestGeneratorGithub{AuthorMatch: &notBot, AuthorTeams: []string{"platform"}}))
+	assert.Equal(t, map[string]int{"alice": 1, "bob": 1, "carol": 1}, membershipRequests)
+}
@@ -1,6 +1,10 @@
 package pull_request
 
-import "context"
+import (
+	"context"
+	"regexp"
+	"time"
+)
 
 type PullRequest struct {
 	// Number is a number that will be the ID of the pull request.
@@ -9,9 +13,32 @@ type PullRequest struct {
 	Branch string
 	// HeadSHA is the SHA of the HEAD from which the pull request originated.
 	HeadSHA string
+	// Title is the title of the pull request.
+	Title string
+	// Author is the username of the user who opened the pull request.
+	Author string
+	// URL is the address of the web page of the pull request.
+	URL string
+	// TargetBranch is the name of the branch the pull request is to be merged into.
+	TargetBranch string
+	// CreatedAt is the time the pull request was opened.
+	CreatedAt time.Time
+	// UpdatedAt is the time the pull request was last updated.
+	UpdatedAt time.Time
+	// Labels are the names of the labels of the pull request.
+	Labels []string
 }
 
 type PullRequestService interface {
 	// List gets a list of pull requests.
 	List(ctx context.Context) ([]*PullRequest, error)
 }
+
+// A compiled version of PullRequestGeneratorFilter for performance.
+type Filter struct {
+	BranchMatch       *regexp.Regexp
+	TargetBranchMatch *regexp.Regexp
+	Labels            []string
+	Authors           []string
+	MaxAge            *time.Duration
+}
@@ -0,0 +1,75 @@
+package pull_request
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	scmRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "argocd_appset_pull_request_scm_requests_total",
+			Help: "Number of API requests made by pull request services to SCM providers.",
+		},
+		[]string{"provider", "endpoint", "code"},
+	)
+	scmRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "argocd_appset_pull_request_scm_request_duration_seconds",
+			Help:    "Latency of API requests made by pull request services to SCM providers.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"provider", "endpoint"},
+	)
+	scmRateLimitRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "argocd_appset_pull_request_scm_rate_limit_remaining",
+			Help: "Number of API requests remaining in the current rate limit window, as last reported by the SCM provider.",
+		},
+		[]string{"provider"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(scmRequestsTotal, scmRequestDuration, scmRateLimitRemaining)
+}
+
+type endpointContextKey struct{}
+
+// withEndpoint tags requests made with the returned context with the given endpoint name, which is used as the
+// endpoint label of the SCM request metrics. This keeps the label bounded regardless of repository names or IDs
+// in the request path.
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointContextKey{}, endpoint)
+}
+
+func endpointFromContext(ctx context.Context) string {
+	if endpoint, ok := ctx.Value(endpointContextKey{}).(string); ok {
+		return endpoint
+	}
+	return "other"
+}
+
+// metricsTransport records the count, status code and latency of every request sent through it.
+type metricsTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointFromContext(req.Context())
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	scmRequestDuration.WithLabelValues(t.provider, endpoint).Observe(time.Since(start).Seconds())
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	scmRequestsTotal.WithLabelValues(t.provider, endpoint, code).Inc()
+	return resp, err
+}
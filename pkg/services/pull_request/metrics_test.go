@@ -0,0 +1,39 @@
+package pull_request
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := newHTTPClient("test", "")
+	assert.NoError(t, err)
+
+	get := func(ctx context.Context, path string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+path, nil)
+		assert.NoError(t, err)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	get(withEndpoint(context.Background(), "list_pull_requests"), "/")
+	get(withEndpoint(context.Background(), "list_pull_requests"), "/")
+	get(withEndpoint(context.Background(), "list_pull_requests"), "/missing")
+	get(context.Background(), "/")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(scmRequestsTotal.WithLabelValues("test", "list_pull_requests", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(scmRequestsTotal.WithLabelValues("test", "list_pull_requests", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(scmRequestsTotal.WithLabelValues("test", "other", "200")))
+}
@@ -0,0 +1,136 @@
+package pull_request
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pluginListPath is the path, relative to the plugin's base URL, of the endpoint listing pull requests.
+const pluginListPath = "/api/v1/pullrequests.list"
+
+type PluginService struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	input   map[string]string
+}
+
+var _ PullRequestService = (*PluginService)(nil)
+
+// pluginListRequest is the body posted to the plugin.
+type pluginListRequest struct {
+	Input map[string]string `json:"input"`
+}
+
+// pluginListResponse is the body the plugin is expected to reply with.
+type pluginListResponse struct {
+	PullRequests []pluginPullRequest `json:"pullRequests"`
+}
+
+type pluginPullRequest struct {
+	Number       int       `json:"number"`
+	Branch       string    `json:"branch"`
+	HeadSHA      string    `json:"headSHA"`
+	Title        string    `json:"title"`
+	Author       string    `json:"author"`
+	URL          string    `json:"url"`
+	TargetBranch string    `json:"targetBranch"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	Labels       []string  `json:"labels"`
+}
+
+// NewPluginService returns a service getting pull requests from an external HTTP service at baseURL. input is sent
+// along with every request, and token, if not empty, as a bearer token.
+func NewPluginService(ctx context.Context, baseURL, token string, input map[string]string) (PullRequestService, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("plugin base URL is required")
+	}
+	client, err := newHTTPClient("plugin", "")
+	if err != nil {
+		return nil, err
+	}
+	return &PluginService{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		input:   input,
+	}, nil
+}
+
+func (p *PluginService) List(ctx context.Context) ([]*PullRequest, error) {
+	body, err := json.Marshal(pluginListRequest{Input: p.input})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(withEndpoint(ctx, "list_pull_requests"), http.MethodPost, p.baseURL+pluginListPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests from plugin %s: %w", p.baseURL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pull requests from plugin %s: %v", p.baseURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error listing pull requests from plugin %s: unexpected status %d: %s", p.baseURL, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var list pluginListResponse
+	if err := json.Unmarshal(respBody, &list); err != nil {
+		return nil, fmt.Errorf("error decoding response of plugin %s: %v", p.baseURL, err)
+	}
+	pullRequests := make([]*PullRequest, 0, len(list.PullRequests))
+	for i, pull := range list.PullRequests {
+		if err := pull.validate(); err != nil {
+			return nil, fmt.Errorf("invalid pull request %d in response of plugin %s: %v", i, p.baseURL, err)
+		}
+		pullRequests = append(pullRequests, &PullRequest{
+			Number:       pull.Number,
+			Branch:       pull.Branch,
+			HeadSHA:      pull.HeadSHA,
+			Title:        pull.Title,
+			Author:       pull.Author,
+			URL:          pull.URL,
+			TargetBranch: pull.TargetBranch,
+			CreatedAt:    pull.CreatedAt,
+			UpdatedAt:    pull.UpdatedAt,
+			Labels:       pull.Labels,
+		})
+	}
+	return pullRequests, nil
+}
+
+// validate checks that the fields the generator can't do without are set. Pull request numbers start at 1.
+func (p *pluginPullRequest) validate() error {
+	var missing []string
+	if p.Number <= 0 {
+		missing = append(missing, "number")
+	}
+	if p.Branch == "" {
+		missing = append(missing, "branch")
+	}
+	if p.HeadSHA == "" {
+		missing = append(missing, "headSHA")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginList(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/pullrequests.list", r.URL.Path)
+		assert.Equal(t, "Bearer plugin-token", r.Header.Get("Authorization"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"input": {"project": "web"}}`, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"pullRequests": [
+			{
+				"number": 7,
+				"branch": "feature",
+				"headSHA": "089d92cbf9ff857a39e6feccd32798ca700fb958",
+				"title": "Add feature",
+				"author": "jdoe",
+				"url": "https://review.example.com/7",
+				"targetBranch": "main",
+				"createdAt": "2021-11-02T10:00:00Z",
+				"labels": ["preview"]
+			}
+		]}`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewPluginService(context.Background(), ts.URL+"/", "plugin-token", map[string]string{"project": "web"})
+	assert.NoError(t, err)
+	pullRequests, err := svc.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*PullRequest{
+		{
+			Number:       7,
+			Branch:       "feature",
+			HeadSHA:      "089d92cbf9ff857a39e6feccd32798ca700fb958",
+			Title:        "Add feature",
+			Author:       "jdoe",
+			URL:          "https://review.example.com/7",
+			TargetBranch: "main",
+			CreatedAt:    time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC),
+			Labels:       []string{"preview"},
+		},
+	}, pullRequests)
+}
+
+func TestPluginListError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "forbidden")
+	}))
+	defer ts.Close()
+
+	svc, err := NewPluginService(context.Background(), ts.URL, "", nil)
+	assert.NoError(t, err)
+	_, err = svc.List(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("error listing pull requests from plugin %s: unexpected status 403: forbidden", ts.URL))
+}
+
+func TestPluginListMissingRequiredFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"pullRequests": [
+			{"number": 7, "branch": "feature", "headSHA": "089d92cbf9ff857a39e6feccd32798ca700fb958"},
+			{"title": "Add feature", "branch": "other"}
+		]}`)
+	}))
+	defer ts.Close()
+
+	svc, err := NewPluginService(context.Background(), ts.URL, "", nil)
+	assert.NoError(t, err)
+	_, err = svc.List(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("invalid pull request 1 in response of plugin %s: missing required fields: number, headSHA", ts.URL))
+}
+
+func TestNewPluginServiceRequiresURL(t *testing.T) {
+	_, err := NewPluginService(context.Background(), "", "", nil)
+	assert.Error(t, err)
+}
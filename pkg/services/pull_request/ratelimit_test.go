@@ -0,0 +1,85 @@
+package pull_request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
+)
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+	}))
+	defer ts.Close()
+
+	client, err := newHTTPClient("ratelimit-retry", "")
+	assert.NoError(t, err)
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, float64(4999), testutil.ToFloat64(scmRateLimitRemaining.WithLabelValues("ratelimit-retry")))
+}
+
+func TestRateLimitTransportExhausted(t *testing.T) {
+	requests := 0
+	reset := time.Now().Add(time.Hour).Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+	}))
+	defer ts.Close()
+
+	client, err := newHTTPClient("ratelimit-exhausted", "")
+	assert.NoError(t, err)
+	// The request consuming the last of the quota succeeds ...
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, float64(0), testutil.ToFloat64(scmRateLimitRemaining.WithLabelValues("ratelimit-exhausted")))
+
+	// ... but the next one fails without being sent.
+	_, err = client.Get(ts.URL)
+	var rateLimitErr *ratelimit.Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.InDelta(t, time.Hour.Seconds(), rateLimitErr.RetryAfter.Seconds(), 5)
+	assert.Equal(t, 1, requests)
+}
+
+func TestGithubListRateLimited(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	svc, err := NewGithubService(context.Background(), "", &argoprojiov1alpha1.PullRequestGeneratorGithub{
+		API:   ts.URL,
+		Owner: "myorg",
+		Repo:  "myrepo",
+	})
+	assert.NoError(t, err)
+	_, err = svc.List(context.Background())
+	var rateLimitErr *ratelimit.Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+}
@@ -0,0 +1,138 @@
+package pull_request
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
+	"github.com/argoproj/applicationset/pkg/utils"
+)
+
+// newHTTPClient returns an HTTP client for talking to the SCM provider. If proxy is set, all requests are sent
+// through it; otherwise the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are honored.
+// Requests are recorded in the SCM request metrics under the given provider name, and throttled according to the
+// rate limit reported by the provider. GET requests are revalidated against the shared response cache.
+func newHTTPClient(provider, proxy string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing proxy URL %q: %v", proxy, err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: scheme and host are required", proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	} else {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+	return &http.Client{
+		Transport: &ratelimit.Transport{
+			Next: &cacheTransport{
+				cache: sharedResponseCache,
+				next:  &metricsTransport{provider: provider, next: transport},
+			},
+			OnRemaining: func(remaining int64) {
+				scmRateLimitRemaining.WithLabelValues(provider).Set(float64(remaining))
+			},
+		},
+	}, nil
+}
+
+func compileFilters(filters []argoprojiov1alpha1.PullRequestGeneratorFilter) ([]*Filter, error) {
+	outFilters := make([]*Filter, 0, len(filters))
+	for _, filter := range filters {
+		outFilter := &Filter{
+			Labels:  filter.Labels,
+			Authors: filter.Authors,
+		}
+		var err error
+		if filter.BranchMatch != nil {
+			outFilter.BranchMatch, err = regexp.Compile(*filter.BranchMatch)
+			if err != nil {
+				return nil, fmt.Errorf("error compiling BranchMatch regexp %q: %v", *filter.BranchMatch, err)
+			}
+		}
+		if filter.TargetBranchMatch != nil {
+			outFilter.TargetBranchMatch, err = regexp.Compile(*filter.TargetBranchMatch)
+			if err != nil {
+				return nil, fmt.Errorf("error compiling TargetBranchMatch regexp %q: %v", *filter.TargetBranchMatch, err)
+			}
+		}
+		if filter.MaxAge != nil {
+			maxAge := filter.MaxAge.Duration
+			outFilter.MaxAge = &maxAge
+		}
+		outFilters = append(outFilters, outFilter)
+	}
+	return outFilters, nil
+}
+
+func matchFilter(pullRequest *PullRequest, filter *Filter, now time.Time) bool {
+	if filter.BranchMatch != nil && !filter.BranchMatch.MatchString(pullRequest.Branch) {
+		return false
+	}
+
+	if filter.TargetBranchMatch != nil && !filter.TargetBranchMatch.MatchString(pullRequest.TargetBranch) {
+		return false
+	}
+
+	for _, label := range filter.Labels {
+		if !utils.ContainsString(pullRequest.Labels, label) {
+			return false
+		}
+	}
+
+	if len(filter.Authors) != 0 && !utils.ContainsString(filter.Authors, pullRequest.Author) {
+		return false
+	}
+
+	if filter.MaxAge != nil {
+		// Pull requests without an update time are judged on their creation time, and pass if they have neither.
+		updatedAt := pullRequest.UpdatedAt
+		if updatedAt.IsZero() {
+			updatedAt = pullRequest.CreatedAt
+		}
+		if !updatedAt.IsZero() && now.Sub(updatedAt) > *filter.MaxAge {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListPullRequests lists the pull requests of the service matching any of the filters. Each filter's conditions
+// must all pass for it to match. If there are no filters, all pull requests are returned.
+func ListPullRequests(ctx context.Context, service PullRequestService, filters []argoprojiov1alpha1.PullRequestGeneratorFilter) ([]*PullRequest, error) {
+	compiledFilters, err := compileFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequests, err := service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Special case, if we have no filters, allow everything.
+	if len(compiledFilters) == 0 {
+		return pullRequests, nil
+	}
+
+	now := time.Now()
+	filteredPullRequests := make([]*PullRequest, 0, len(pullRequests))
+	for _, pullRequest := range pullRequests {
+		for _, filter := range compiledFilters {
+			if matchFilter(pullRequest, filter, now) {
+				filteredPullRequests = append(filteredPullRequests, pullRequest)
+				break
+			}
+		}
+	}
+	return filteredPullRequests, nil
+}
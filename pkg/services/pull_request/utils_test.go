@@ -0,0 +1,176 @@
+package pull_request
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	cases := []struct {
+		name          string
+		proxy         string
+		expectedProxy string
+		hasError      bool
+	}{
+		{
+			name:          "explicit proxy",
+			proxy:         "http://proxy.example.com:3128",
+			expectedProxy: "http://proxy.example.com:3128",
+		},
+		{
+			name:     "proxy without scheme",
+			proxy:    "proxy.example.com:3128",
+			hasError: true,
+		},
+		{
+			name:     "unparsable proxy",
+			proxy:    "http://proxy example.com",
+			hasError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := newHTTPClient("github", c.proxy)
+			if c.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/", nil)
+			proxyURL, err := client.Transport.(*ratelimit.Transport).Next.(*cacheTransport).next.(*metricsTransport).next.(*http.Transport).Proxy(req)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedProxy, proxyURL.String())
+		})
+	}
+}
+
+func TestListPullRequests(t *testing.T) {
+	now := time.Now()
+	pullRequests := []*PullRequest{
+		{
+			Number:       1,
+			Branch:       "feature-a",
+			TargetBranch: "main",
+			Author:       "alice",
+			Labels:       []string{"preview", "backend"},
+			UpdatedAt:    now.Add(-time.Hour),
+		},
+		{
+			Number:       2,
+			Branch:       "feature-b",
+			TargetBranch: "release-1.0",
+			Author:       "bob",
+			Labels:       []string{"preview"},
+			UpdatedAt:    now.Add(-72 * time.Hour),
+		},
+		{
+			Number:       3,
+			Branch:       "renovate/foo",
+			TargetBranch: "main",
+			Author:       "renovate[bot]",
+		},
+		{
+			Number:       4,
+			Branch:       "stale",
+			TargetBranch: "develop",
+			Author:       "carol",
+			CreatedAt:    now.Add(-72 * time.Hour),
+		},
+	}
+
+	cases := []struct {
+		name            string
+		filters         []argoprojiov1alpha1.PullRequestGeneratorFilter
+		expectedNumbers []int
+		hasError        bool
+	}{
+		{
+			name:            "no filters",
+			expectedNumbers: []int{1, 2, 3, 4},
+		},
+		{
+			name: "branch match",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{BranchMatch: toPtr("^feature-")},
+			},
+			expectedNumbers: []int{1, 2},
+		},
+		{
+			name: "target branch match",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{TargetBranchMatch: toPtr("^release-")},
+			},
+			expectedNumbers: []int{2},
+		},
+		{
+			name: "labels must all be present",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{Labels: []string{"preview", "backend"}},
+			},
+			expectedNumbers: []int{1},
+		},
+		{
+			name: "authors",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{Authors: []string{"bob", "renovate[bot]"}},
+			},
+			expectedNumbers: []int{2, 3},
+		},
+		{
+			name: "max age falls back to creation time and ignores pull requests without either",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{MaxAge: &metav1.Duration{Duration: 24 * time.Hour}},
+			},
+			expectedNumbers: []int{1, 3},
+		},
+		{
+			name: "conditions are AND'd",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{BranchMatch: toPtr("^feature-"), TargetBranchMatch: toPtr("^main$")},
+			},
+			expectedNumbers: []int{1},
+		},
+		{
+			name: "filters are OR'd",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{TargetBranchMatch: toPtr("^release-")},
+				{Authors: []string{"renovate[bot]"}},
+			},
+			expectedNumbers: []int{2, 3},
+		},
+		{
+			name: "invalid regexp",
+			filters: []argoprojiov1alpha1.PullRequestGeneratorFilter{
+				{BranchMatch: toPtr("(")},
+			},
+			hasError: true,
+		},
+	}
+
+	for _, c := range cases {
+		cc := c
+		t.Run(cc.name, func(t *testing.T) {
+			svc, _ := NewFakeService(context.Background(), pullRequests, nil)
+			got, err := ListPullRequests(context.Background(), svc, cc.filters)
+			if cc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			numbers := []int{}
+			for _, pullRequest := range got {
+				numbers = append(numbers, pullRequest.Number)
+			}
+			assert.Equal(t, cc.expectedNumbers, numbers)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package ratelimit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+)
+
+// FromGithub converts the rate limit errors of go-github, which tracks the rate limit of its client and fails
+// requests without sending them once it is exhausted, to an Error. Other errors are returned as is.
+func FromGithub(err error) error {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return &Error{RetryAfter: positive(time.Until(rateLimitErr.Rate.Reset.Time))}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return &Error{RetryAfter: positive(*abuseErr.RetryAfter)}
+		}
+		return &Error{RetryAfter: defaultWait}
+	}
+	return err
+}
+
+// positive returns wait, or else a second so that requeuing doesn't retry right away.
+func positive(wait time.Duration) time.Duration {
+	if wait <= 0 {
+		return time.Second
+	}
+	return wait
+}
@@ -0,0 +1,222 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxWait is the longest a request waits for a rate limit to reset. Longer waits are left to the controller,
+	// which requeues the ApplicationSet rather than holding up a reconcile worker.
+	maxWait = 10 * time.Second
+	// defaultWait is assumed when a provider rejects a request as rate limited without saying for how long.
+	defaultWait = time.Minute
+)
+
+// Error is returned when the rate limit of the SCM provider is exhausted and doesn't reset soon enough to wait for
+// it.
+type Error struct {
+	// RetryAfter is the time until the rate limit resets.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %v", e.RetryAfter.Round(time.Second))
+}
+
+// sharedResets is used by the transports without resets of their own, since the clients of SCM providers are
+// created anew on every reconcile.
+var sharedResets = NewResets()
+
+// Resets records the time until which requests are expected to be rejected, by API host and credentials.
+type Resets struct {
+	mu     sync.Mutex
+	resets map[string]time.Time
+}
+
+func NewResets() *Resets {
+	return &Resets{resets: map[string]time.Time{}}
+}
+
+func (r *Resets) get(key string) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resets[key]
+}
+
+// extend records that requests are rejected until resetAt, unless they already are for longer. Resets which have
+// passed are forgotten, so that the credentials of deleted ApplicationSets don't pile up.
+func (r *Resets) extend(key string, resetAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for k, v := range r.resets {
+		if !v.After(now) {
+			delete(r.resets, k)
+		}
+	}
+	if resetAt.After(r.resets[key]) {
+		r.resets[key] = resetAt
+	}
+}
+
+// credentialHeaders are the request headers carrying the credentials of the SCM providers, eg PRIVATE-TOKEN for
+// GitLab.
+var credentialHeaders = []string{"Authorization", "Private-Token"}
+
+// key identifies the rate limit a request counts against by the API host and the credentials, which are hashed to
+// avoid keeping them in memory.
+func key(req *http.Request) string {
+	hash := sha256.New()
+	for _, name := range credentialHeaders {
+		fmt.Fprintf(hash, "%s: %s\n", name, strings.Join(req.Header.Values(name), ","))
+	}
+	return req.URL.Host + " " + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Transport tracks the rate limit headers returned by the SCM provider. Once the quota is exhausted, requests are
+// delayed until it resets, or fail with an Error without being sent if that is too far off. Requests rejected as
+// rate limited are retried once if the wait is short enough. Transports must be given the requests after their
+// credentials are set, so that the quota of each credential is tracked separately.
+type Transport struct {
+	// Next sends the requests. Defaults to http.DefaultTransport.
+	Next http.RoundTripper
+	// Resets is shared with the transports of later reconciles, so that they keep throttling the same credentials.
+	// Defaults to resets shared by the whole controller.
+	Resets *Resets
+	// OnRemaining, if set, is called with the remaining quota reported by each response.
+	OnRemaining func(remaining int64)
+}
+
+// NewTransport returns a transport sending requests with next, and sharing the resets of the controller.
+func NewTransport(next http.RoundTripper) *Transport {
+	return &Transport{Next: next}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := key(req)
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForReset(req.Context(), key); err != nil {
+			return nil, err
+		}
+		resp, err := t.next().RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		wait, limited := t.observe(resp, key)
+		if !limited {
+			return resp, nil
+		}
+		// Retrying requires resending the body, which is only possible if it can be recreated.
+		if attempt > 0 || wait > maxWait || (req.Body != nil && req.GetBody == nil) {
+			resp.Body.Close()
+			return nil, &Error{RetryAfter: wait}
+		}
+		resp.Body.Close()
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next == nil {
+		return http.DefaultTransport
+	}
+	return t.Next
+}
+
+func (t *Transport) resets() *Resets {
+	if t.Resets == nil {
+		return sharedResets
+	}
+	return t.Resets
+}
+
+// waitForReset blocks until the rate limit is expected to have reset, or returns an Error if that would take longer
+// than maxWait.
+func (t *Transport) waitForReset(ctx context.Context, key string) error {
+	wait := time.Until(t.resets().get(key))
+	if wait <= 0 {
+		return nil
+	}
+	if wait > maxWait {
+		return &Error{RetryAfter: wait}
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe records the remaining quota reported in the response headers, and returns whether the request was
+// rejected as rate limited and, if it was or the quota is now exhausted, how long until it resets. Both the
+// X-RateLimit-* headers of GitHub and Gitea and the RateLimit-* headers of GitLab are understood, as well as the
+// standard Retry-After header.
+func (t *Transport) observe(resp *http.Response, key string) (time.Duration, bool) {
+	now := time.Now()
+	remaining, hasRemaining := headerInt(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if hasRemaining && t.OnRemaining != nil {
+		t.OnRemaining(remaining)
+	}
+	exhausted := hasRemaining && remaining <= 0
+
+	var wait time.Duration
+	known := false
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+		wait, known = retryAfter, true
+	} else if reset, ok := headerInt(resp.Header, "X-RateLimit-Reset", "RateLimit-Reset"); ok && exhausted {
+		wait, known = time.Unix(reset, 0).Sub(now), true
+	}
+
+	limited := resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && (exhausted || resp.Header.Get("Retry-After") != ""))
+	if limited && !known {
+		wait = defaultWait
+	}
+	if wait > 0 && (limited || exhausted) {
+		t.resets().extend(key, now.Add(wait))
+	}
+	return wait, limited
+}
+
+// headerInt returns the integer value of the first of the headers that is set.
+func headerInt(header http.Header, names ...string) (int64, bool) {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			i, err := strconv.ParseInt(value, 10, 64)
+			return i, err == nil
+		}
+	}
+	return 0, false
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), true
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v35/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportRetries(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+	}))
+	defer ts.Close()
+
+	var remaining int64
+	client := &http.Client{Transport: &Transport{Resets: NewResets(), OnRemaining: func(r int64) { remaining = r }}}
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, int64(4999), remaining)
+}
+
+func TestTransportExhausted(t *testing.T) {
+	requests := 0
+	reset := time.Now().Add(time.Hour).Unix()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{Resets: NewResets()}}
+	// The request consuming the last of the quota succeeds ...
+	resp, err := client.Get(ts.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	// ... but the next one fails without being sent.
+	_, err = client.Get(ts.URL)
+	var rateLimitErr *Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.InDelta(t, time.Hour.Seconds(), rateLimitErr.RetryAfter.Seconds(), 5)
+	assert.Equal(t, 1, requests)
+}
+
+func TestTransportRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{Resets: NewResets()}}
+	_, err := client.Get(ts.URL)
+	var rateLimitErr *Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.Equal(t, defaultWait, rateLimitErr.RetryAfter)
+}
+
+func TestTransportWaitsForShortReset(t *testing.T) {
+	transport := &Transport{Resets: NewResets()}
+	resetAt := time.Now().Add(50 * time.Millisecond)
+	transport.Resets.extend("key", resetAt)
+	assert.NoError(t, transport.waitForReset(context.Background(), "key"))
+	assert.False(t, time.Now().Before(resetAt))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	transport.Resets.extend("key", time.Now().Add(time.Second))
+	assert.Equal(t, context.Canceled, transport.waitForReset(ctx, "key"))
+}
+
+func TestTransportSharedAcrossClients(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	}))
+	defer ts.Close()
+
+	get := func(header, token string) error {
+		// Clients are created anew on every reconcile.
+		client := &http.Client{Transport: NewTransport(nil)}
+		req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+		assert.NoError(t, err)
+		req.Header.Set(header, token)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	assert.NoError(t, get("Authorization", "token a"))
+	// The quota of the credentials is still exhausted in a later reconcile ...
+	var rateLimitErr *Error
+	assert.True(t, errors.As(get("Authorization", "token a"), &rateLimitErr))
+	// ... but not that of other credentials, including those of GitLab.
+	assert.NoError(t, get("Authorization", "token b"))
+	assert.NoError(t, get("Private-Token", "a"))
+	assert.True(t, errors.As(get("Private-Token", "a"), &rateLimitErr))
+	assert.Equal(t, 3, requests)
+}
+
+func TestResetsForgetPassedResets(t *testing.T) {
+	resets := NewResets()
+	resets.extend("old", time.Now().Add(-time.Second))
+	resets.extend("new", time.Now().Add(time.Hour))
+	assert.NotContains(t, resets.resets, "old")
+	assert.Contains(t, resets.resets, "new")
+}
+
+func TestFromGithub(t *testing.T) {
+	err := FromGithub(&github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(time.Hour)}}})
+	var rateLimitErr *Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.InDelta(t, time.Hour.Seconds(), rateLimitErr.RetryAfter.Seconds(), 5)
+
+	retryAfter := 30 * time.Second
+	err = FromGithub(fmt.Errorf("error listing: %w", &github.AbuseRateLimitError{RetryAfter: &retryAfter}))
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.Equal(t, retryAfter, rateLimitErr.RetryAfter)
+
+	other := errors.New("not found")
+	assert.Equal(t, other, FromGithub(other))
+}
@@ -0,0 +1,255 @@
+package scm_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	pathpkg "path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+)
+
+const (
+	// codeCommitResourceType is the resource type of CodeCommit repositories in the Resource Groups Tagging API.
+	codeCommitResourceType = "codecommit:repository"
+	// codeCommitBatchSize is the maximum number of repositories BatchGetRepositories gets at once.
+	codeCommitBatchSize = 25
+)
+
+type AWSCodeCommitProvider struct {
+	codeCommitClient codecommitiface.CodeCommitAPI
+	taggingClient    resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	tagFilters       []*resourcegroupstaggingapi.TagFilter
+	allBranches      bool
+}
+
+var _ SCMProviderService = &AWSCodeCommitProvider{}
+
+// NewAWSCodeCommitProvider returns a provider listing CodeCommit repositories. The credentials are found by the
+// default credential chain of the AWS SDK, which includes IAM roles for service accounts (IRSA). If role is set, it
+// is assumed with those credentials.
+func NewAWSCodeCommitProvider(ctx context.Context, tagFilters []argoprojiov1alpha1.SCMProviderGeneratorAWSTagFilter, role, region string, allBranches bool) (*AWSCodeCommitProvider, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating AWS session: %v", err)
+	}
+	config := aws.NewConfig()
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+	if role != "" {
+		config = config.WithCredentials(stscreds.NewCredentials(sess, role))
+	}
+	return &AWSCodeCommitProvider{
+		codeCommitClient: codecommit.New(sess, config),
+		taggingClient:    resourcegroupstaggingapi.New(sess, config),
+		tagFilters:       compileAWSTagFilters(tagFilters),
+		allBranches:      allBranches,
+	}, nil
+}
+
+func (p *AWSCodeCommitProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	names, err := p.listRepoNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories: %v", err)
+	}
+
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	for start := 0; start < len(names); start += codeCommitBatchSize {
+		end := start + codeCommitBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		output, err := p.codeCommitClient.BatchGetRepositoriesWithContext(ctx, &codecommit.BatchGetRepositoriesInput{
+			RepositoryNames: aws.StringSlice(names[start:end]),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting repositories: %v", err)
+		}
+		for _, codeCommitRepo := range output.Repositories {
+			// Empty repositories have no default branch, and so nothing to generate.
+			if codeCommitRepo.DefaultBranch == nil {
+				continue
+			}
+
+			var url string
+			switch cloneProtocol {
+			// Default to HTTPS if unspecified (i.e. if ""), since SSH requires keys of IAM users.
+			case "", "https":
+				url = aws.StringValue(codeCommitRepo.CloneUrlHttp)
+			case "ssh":
+				url = aws.StringValue(codeCommitRepo.CloneUrlSsh)
+			default:
+				return nil, fmt.Errorf("unknown clone protocol for AWS CodeCommit %v", cloneProtocol)
+			}
+
+			branches, err := p.listBranches(ctx, codeCommitRepo)
+			if err != nil {
+				if err := repoErrs.add(fmt.Errorf("error listing branches for %s: %v", aws.StringValue(codeCommitRepo.RepositoryName), err)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			for _, branch := range branches {
+				repos = append(repos, &Repository{
+					Organization: aws.StringValue(codeCommitRepo.AccountId),
+					Repository:   aws.StringValue(codeCommitRepo.RepositoryName),
+					URL:          url,
+					Branch:       aws.StringValue(branch.BranchName),
+					SHA:          aws.StringValue(branch.CommitId),
+				})
+			}
+		}
+	}
+	return repos, repoErrs.err()
+}
+
+func (p *AWSCodeCommitProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	path = strings.Trim(pathpkg.Clean("/"+path), "/")
+	if path == "" {
+		return true, nil
+	}
+	// Files and folders are fetched differently, so look for the path in the listing of its parent folder instead.
+	parent, name := pathpkg.Split(path)
+	output, err := p.codeCommitClient.GetFolderWithContext(ctx, &codecommit.GetFolderInput{
+		RepositoryName:  aws.String(repo.Repository),
+		CommitSpecifier: aws.String(repo.Branch),
+		FolderPath:      aws.String("/" + strings.TrimSuffix(parent, "/")),
+	})
+	// A missing parent folder is not an error here, just a normal false.
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == codecommit.ErrCodeFolderDoesNotExistException {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, folder := range output.SubFolders {
+		if aws.StringValue(folder.RelativePath) == name {
+			return true, nil
+		}
+	}
+	for _, file := range output.Files {
+		if aws.StringValue(file.RelativePath) == name {
+			return true, nil
+		}
+	}
+	for _, link := range output.SymbolicLinks {
+		if aws.StringValue(link.RelativePath) == name {
+			return true, nil
+		}
+	}
+	for _, subModule := range output.SubModules {
+		if aws.StringValue(subModule.RelativePath) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listRepoNames lists the names of all repositories, or of the repositories matching the tag filters if there are any.
+func (p *AWSCodeCommitProvider) listRepoNames(ctx context.Context) ([]string, error) {
+	names := []string{}
+	if len(p.tagFilters) == 0 {
+		err := p.codeCommitClient.ListRepositoriesPagesWithContext(ctx, &codecommit.ListRepositoriesInput{}, func(output *codecommit.ListRepositoriesOutput, _ bool) bool {
+			for _, repo := range output.Repositories {
+				names = append(names, aws.StringValue(repo.RepositoryName))
+			}
+			return true
+		})
+		return names, err
+	}
+
+	var parseErr error
+	err := p.taggingClient.GetResourcesPagesWithContext(ctx, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: aws.StringSlice([]string{codeCommitResourceType}),
+		TagFilters:          p.tagFilters,
+	}, func(output *resourcegroupstaggingapi.GetResourcesOutput, _ bool) bool {
+		for _, mapping := range output.ResourceTagMappingList {
+			// The resource of the ARN of a repository is its name.
+			repoARN, err := arn.Parse(aws.StringValue(mapping.ResourceARN))
+			if err != nil {
+				parseErr = fmt.Errorf("error parsing ARN %q: %v", aws.StringValue(mapping.ResourceARN), err)
+				return false
+			}
+			names = append(names, repoARN.Resource)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, parseErr
+}
+
+func (p *AWSCodeCommitProvider) listBranches(ctx context.Context, repo *codecommit.RepositoryMetadata) ([]*codecommit.BranchInfo, error) {
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	branchNames := []string{aws.StringValue(repo.DefaultBranch)}
+	if p.allBranches {
+		// Otherwise, scrape the ListBranches API, which only returns the names of the branches.
+		branchNames = []string{}
+		err := p.codeCommitClient.ListBranchesPagesWithContext(ctx, &codecommit.ListBranchesInput{
+			RepositoryName: repo.RepositoryName,
+		}, func(output *codecommit.ListBranchesOutput, _ bool) bool {
+			branchNames = append(branchNames, aws.StringValueSlice(output.Branches)...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	branches := make([]*codecommit.BranchInfo, 0, len(branchNames))
+	for _, branchName := range branchNames {
+		output, err := p.codeCommitClient.GetBranchWithContext(ctx, &codecommit.GetBranchInput{
+			RepositoryName: repo.RepositoryName,
+			BranchName:     aws.String(branchName),
+		})
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, output.Branch)
+	}
+	return branches, nil
+}
+
+// compileAWSTagFilters converts the tag filters to those of the Resource Groups Tagging API. Filters of the same key
+// are merged, so that any of their values match.
+func compileAWSTagFilters(tagFilters []argoprojiov1alpha1.SCMProviderGeneratorAWSTagFilter) []*resourcegroupstaggingapi.TagFilter {
+	compiled := []*resourcegroupstaggingapi.TagFilter{}
+	byKey := map[string]*resourcegroupstaggingapi.TagFilter{}
+	anyValue := map[string]bool{}
+	for _, tagFilter := range tagFilters {
+		compiledFilter, ok := byKey[tagFilter.Key]
+		if !ok {
+			compiledFilter = &resourcegroupstaggingapi.TagFilter{Key: aws.String(tagFilter.Key)}
+			byKey[tagFilter.Key] = compiledFilter
+			compiled = append(compiled, compiledFilter)
+		}
+		if tagFilter.Value == "" {
+			anyValue[tagFilter.Key] = true
+		} else {
+			compiledFilter.Values = append(compiledFilter.Values, aws.String(tagFilter.Value))
+		}
+	}
+	// A filter without values matches any value.
+	for key := range anyValue {
+		byKey[key].Values = nil
+	}
+	return compiled
+}
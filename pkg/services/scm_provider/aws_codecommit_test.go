@@ -0,0 +1,269 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/codecommit"
+	"github.com/aws/aws-sdk-go/service/codecommit/codecommitiface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+)
+
+// fakeCodeCommitClient implements the parts of the CodeCommit client used by the provider.
+type fakeCodeCommitClient struct {
+	codecommitiface.CodeCommitAPI
+	repos []*codecommit.RepositoryMetadata
+	// branches are keyed by repository name, and then branch name, to their commit.
+	branches map[string]map[string]string
+	// folders are keyed by repository name and folder path, eg "repo:/apps".
+	folders map[string]*codecommit.GetFolderOutput
+}
+
+func (f *fakeCodeCommitClient) ListRepositoriesPagesWithContext(_ aws.Context, _ *codecommit.ListRepositoriesInput, fn func(*codecommit.ListRepositoriesOutput, bool) bool, _ ...request.Option) error {
+	// Return a page per repository to exercise the pagination.
+	for i, repo := range f.repos {
+		output := &codecommit.ListRepositoriesOutput{
+			Repositories: []*codecommit.RepositoryNameIdPair{{RepositoryName: repo.RepositoryName, RepositoryId: repo.RepositoryId}},
+		}
+		if !fn(output, i == len(f.repos)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeCodeCommitClient) BatchGetRepositoriesWithContext(_ aws.Context, input *codecommit.BatchGetRepositoriesInput, _ ...request.Option) (*codecommit.BatchGetRepositoriesOutput, error) {
+	output := &codecommit.BatchGetRepositoriesOutput{}
+	for _, name := range input.RepositoryNames {
+		for _, repo := range f.repos {
+			if aws.StringValue(repo.RepositoryName) == aws.StringValue(name) {
+				output.Repositories = append(output.Repositories, repo)
+			}
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeCodeCommitClient) ListBranchesPagesWithContext(_ aws.Context, input *codecommit.ListBranchesInput, fn func(*codecommit.ListBranchesOutput, bool) bool, _ ...request.Option) error {
+	branches, ok := f.branches[aws.StringValue(input.RepositoryName)]
+	if !ok {
+		return awserr.New(codecommit.ErrCodeRepositoryDoesNotExistException, "repository not found", nil)
+	}
+	output := &codecommit.ListBranchesOutput{}
+	for _, name := range []string{"main", "feature"} {
+		if _, ok := branches[name]; ok {
+			output.Branches = append(output.Branches, aws.String(name))
+		}
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeCodeCommitClient) GetBranchWithContext(_ aws.Context, input *codecommit.GetBranchInput, _ ...request.Option) (*codecommit.GetBranchOutput, error) {
+	commit, ok := f.branches[aws.StringValue(input.RepositoryName)][aws.StringValue(input.BranchName)]
+	if !ok {
+		return nil, awserr.New(codecommit.ErrCodeBranchDoesNotExistException, "branch not found", nil)
+	}
+	return &codecommit.GetBranchOutput{Branch: &codecommit.BranchInfo{BranchName: input.BranchName, CommitId: aws.String(commit)}}, nil
+}
+
+func (f *fakeCodeCommitClient) GetFolderWithContext(_ aws.Context, input *codecommit.GetFolderInput, _ ...request.Option) (*codecommit.GetFolderOutput, error) {
+	output, ok := f.folders[aws.StringValue(input.RepositoryName)+":"+aws.StringValue(input.FolderPath)]
+	if !ok {
+		return nil, awserr.New(codecommit.ErrCodeFolderDoesNotExistException, "folder not found", nil)
+	}
+	return output, nil
+}
+
+// fakeTaggingClient implements the parts of the Resource Groups Tagging API client used by the provider.
+type fakeTaggingClient struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	t    *testing.T
+	arns []string
+}
+
+func (f *fakeTaggingClient) GetResourcesPagesWithContext(_ aws.Context, input *resourcegroupstaggingapi.GetResourcesInput, fn func(*resourcegroupstaggingapi.GetResourcesOutput, bool) bool, _ ...request.Option) error {
+	assert.Equal(f.t, []*string{aws.String("codecommit:repository")}, input.ResourceTypeFilters)
+	assert.Equal(f.t, []*resourcegroupstaggingapi.TagFilter{
+		{Key: aws.String("team"), Values: []*string{aws.String("platform")}},
+	}, input.TagFilters)
+	output := &resourcegroupstaggingapi.GetResourcesOutput{}
+	for _, repoARN := range f.arns {
+		output.ResourceTagMappingList = append(output.ResourceTagMappingList, &resourcegroupstaggingapi.ResourceTagMapping{ResourceARN: aws.String(repoARN)})
+	}
+	fn(output, true)
+	return nil
+}
+
+func codeCommitRepo(name, defaultBranch string) *codecommit.RepositoryMetadata {
+	repo := &codecommit.RepositoryMetadata{
+		AccountId:      aws.String("123456789012"),
+		RepositoryId:   aws.String(name + "-id"),
+		RepositoryName: aws.String(name),
+		CloneUrlHttp:   aws.String("https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/" + name),
+		CloneUrlSsh:    aws.String("ssh://git-codecommit.eu-west-1.amazonaws.com/v1/repos/" + name),
+	}
+	if defaultBranch != "" {
+		repo.DefaultBranch = aws.String(defaultBranch)
+	}
+	return repo
+}
+
+func newFakeCodeCommitClient() *fakeCodeCommitClient {
+	return &fakeCodeCommitClient{
+		repos: []*codecommit.RepositoryMetadata{
+			codeCommitRepo("app", "main"),
+			codeCommitRepo("infra", "main"),
+			codeCommitRepo("empty", ""),
+		},
+		branches: map[string]map[string]string{
+			"app": {
+				"main":    "c1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4",
+				"feature": "f0e1d2c3b4a5968778695a4b3c2d1e0f1a2b3c4d",
+			},
+			"infra": {
+				"main": "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+		folders: map[string]*codecommit.GetFolderOutput{
+			"app:/": {
+				SubFolders: []*codecommit.Folder{{RelativePath: aws.String("deploy")}},
+				Files:      []*codecommit.File{{RelativePath: aws.String("README.md")}},
+			},
+			"app:/deploy": {
+				Files: []*codecommit.File{{RelativePath: aws.String("app.yaml")}},
+			},
+		},
+	}
+}
+
+func TestAWSCodeCommitListRepos(t *testing.T) {
+	cases := []struct {
+		name, proto string
+		allBranches bool
+		tagFilters  []argoprojiov1alpha1.SCMProviderGeneratorAWSTagFilter
+		hasError    bool
+		repos       []*Repository
+	}{
+		{
+			name: "blank protocol",
+			repos: []*Repository{
+				{Organization: "123456789012", Repository: "app", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/app", Branch: "main", SHA: "c1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4"},
+				{Organization: "123456789012", Repository: "infra", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra", Branch: "main", SHA: "0123456789abcdef0123456789abcdef01234567"},
+			},
+		},
+		{
+			name:  "ssh protocol",
+			proto: "ssh",
+			repos: []*Repository{
+				{Organization: "123456789012", Repository: "app", URL: "ssh://git-codecommit.eu-west-1.amazonaws.com/v1/repos/app", Branch: "main", SHA: "c1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4"},
+				{Organization: "123456789012", Repository: "infra", URL: "ssh://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra", Branch: "main", SHA: "0123456789abcdef0123456789abcdef01234567"},
+			},
+		},
+		{
+			name:     "other protocol",
+			proto:    "other",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "123456789012", Repository: "app", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/app", Branch: "main", SHA: "c1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4"},
+				{Organization: "123456789012", Repository: "app", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/app", Branch: "feature", SHA: "f0e1d2c3b4a5968778695a4b3c2d1e0f1a2b3c4d"},
+				{Organization: "123456789012", Repository: "infra", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra", Branch: "main", SHA: "0123456789abcdef0123456789abcdef01234567"},
+			},
+		},
+		{
+			name:       "tag filters",
+			tagFilters: []argoprojiov1alpha1.SCMProviderGeneratorAWSTagFilter{{Key: "team", Value: "platform"}},
+			repos: []*Repository{
+				{Organization: "123456789012", Repository: "infra", URL: "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra", Branch: "main", SHA: "0123456789abcdef0123456789abcdef01234567"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := &AWSCodeCommitProvider{
+				codeCommitClient: newFakeCodeCommitClient(),
+				taggingClient: &fakeTaggingClient{
+					t:    t,
+					arns: []string{"arn:aws:codecommit:eu-west-1:123456789012:infra"},
+				},
+				tagFilters:  compileAWSTagFilters(c.tagFilters),
+				allBranches: c.allBranches,
+			}
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.repos, repos)
+			}
+		})
+	}
+}
+
+func TestAWSCodeCommitListReposBranchError(t *testing.T) {
+	client := newFakeCodeCommitClient()
+	delete(client.branches["infra"], "main")
+	provider := &AWSCodeCommitProvider{codeCommitClient: client, tagFilters: compileAWSTagFilters(nil)}
+
+	repos, err := provider.ListRepos(context.Background(), "", true)
+	var repoErrs *RepositoryErrors
+	if assert.ErrorAs(t, err, &repoErrs) {
+		assert.EqualError(t, repoErrs, fmt.Sprintf("error listing branches for infra: %v", awserr.New(codecommit.ErrCodeBranchDoesNotExistException, "branch not found", nil)))
+	}
+	assert.Len(t, repos, 1)
+	assert.Equal(t, "app", repos[0].Repository)
+}
+
+func TestAWSCodeCommitHasPath(t *testing.T) {
+	provider := &AWSCodeCommitProvider{codeCommitClient: newFakeCodeCommitClient()}
+	repo := &Repository{
+		Organization: "123456789012",
+		Repository:   "app",
+		Branch:       "main",
+	}
+
+	cases := []struct {
+		path   string
+		exists bool
+	}{
+		{path: "deploy", exists: true},
+		{path: "deploy/", exists: true},
+		{path: "/README.md", exists: true},
+		{path: "deploy/app.yaml", exists: true},
+		{path: "notathing", exists: false},
+		{path: "notathing/app.yaml", exists: false},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			ok, err := provider.RepoHasPath(context.Background(), repo, c.path)
+			assert.NoError(t, err)
+			assert.Equal(t, c.exists, ok)
+		})
+	}
+}
+
+func TestCompileAWSTagFilters(t *testing.T) {
+	compiled := compileAWSTagFilters([]argoprojiov1alpha1.SCMProviderGeneratorAWSTagFilter{
+		{Key: "team", Value: "platform"},
+		{Key: "env"},
+		{Key: "team", Value: "security"},
+		{Key: "env", Value: "prod"},
+	})
+	assert.Equal(t, []*resourcegroupstaggingapi.TagFilter{
+		{Key: aws.String("team"), Values: []*string{aws.String("platform"), aws.String("security")}},
+		{Key: aws.String("env")},
+	}, compiled)
+}
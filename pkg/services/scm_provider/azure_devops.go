@@ -0,0 +1,162 @@
+package scm_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+)
+
+// defaultAzureDevOpsURL is the URL of Azure DevOps Services, which the organization name is appended to.
+const defaultAzureDevOpsURL = "https://dev.azure.com"
+
+type AzureDevOpsProvider struct {
+	client       git.Client
+	organization string
+	project      string
+	allBranches  bool
+}
+
+var _ SCMProviderService = &AzureDevOpsProvider{}
+
+// NewAzureDevOpsProvider returns a provider listing the Git repositories of an Azure DevOps project. If token is
+// set, it is used as a personal access token, otherwise requests are made anonymously.
+func NewAzureDevOpsProvider(ctx context.Context, organization, project, token, url string, allBranches bool) (*AzureDevOpsProvider, error) {
+	if url == "" {
+		url = defaultAzureDevOpsURL
+	}
+	organizationURL := strings.TrimSuffix(url, "/") + "/" + organization
+	var connection *azuredevops.Connection
+	if token == "" {
+		connection = azuredevops.NewAnonymousConnection(organizationURL)
+	} else {
+		connection = azuredevops.NewPatConnection(organizationURL, token)
+	}
+	client, err := git.NewClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureDevOpsProvider{client: client, organization: organization, project: project, allBranches: allBranches}, nil
+}
+
+func (a *AzureDevOpsProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	azureRepos, err := a.client.GetRepositories(ctx, git.GetRepositoriesArgs{Project: &a.project})
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories for %s/%s: %v", a.organization, a.project, err)
+	}
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	for _, azureRepo := range *azureRepos {
+		if azureRepo.Name == nil || azureRepo.Id == nil {
+			continue
+		}
+		// Empty repositories have no default branch, and so nothing to generate.
+		if azureRepo.DefaultBranch == nil {
+			continue
+		}
+
+		var url string
+		switch cloneProtocol {
+		// Default to SSH if unspecified (i.e. if "").
+		case "", "ssh":
+			url = stringValue(azureRepo.SshUrl)
+		case "https":
+			url = stringValue(azureRepo.RemoteUrl)
+		default:
+			return nil, fmt.Errorf("unknown clone protocol for Azure DevOps %v", cloneProtocol)
+		}
+
+		branches, err := a.listBranches(ctx, &azureRepo)
+		if err != nil {
+			if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %v", a.project, *azureRepo.Name, err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, branch := range branches {
+			if branch.Name == nil || branch.Commit == nil {
+				continue
+			}
+			repos = append(repos, &Repository{
+				Organization: a.organization,
+				Repository:   *azureRepo.Name,
+				URL:          url,
+				Branch:       *branch.Name,
+				SHA:          stringValue(branch.Commit.CommitId),
+			})
+		}
+	}
+	return repos, repoErrs.err()
+}
+
+func (a *AzureDevOpsProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	_, err := a.client.GetItem(ctx, git.GetItemArgs{
+		RepositoryId: &repo.Repository,
+		Project:      &a.project,
+		Path:         &path,
+		VersionDescriptor: &git.GitVersionDescriptor{
+			Version:     &repo.Branch,
+			VersionType: &git.GitVersionTypeValues.Branch,
+		},
+	})
+	// 404s are not an error here, just a normal false.
+	if isAzureDevOpsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *AzureDevOpsProvider) listBranches(ctx context.Context, repo *git.GitRepository) ([]git.GitBranchStats, error) {
+	repoID := repo.Id.String()
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	if !a.allBranches {
+		defaultBranchName := strings.TrimPrefix(*repo.DefaultBranch, "refs/heads/")
+		branch, err := a.client.GetBranch(ctx, git.GetBranchArgs{
+			RepositoryId: &repoID,
+			Project:      &a.project,
+			Name:         &defaultBranchName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []git.GitBranchStats{*branch}, nil
+	}
+	// Otherwise, get the statistics of all branches, which include their commits.
+	branches, err := a.client.GetBranches(ctx, git.GetBranchesArgs{
+		RepositoryId: &repoID,
+		Project:      &a.project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *branches, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// isAzureDevOpsNotFound returns true if err is an error of the Azure DevOps API for a 404 response. The client
+// returns WrappedError both as a value and as a pointer.
+func isAzureDevOpsNotFound(err error) bool {
+	var statusCode *int
+	var wrappedErr azuredevops.WrappedError
+	var wrappedErrPtr *azuredevops.WrappedError
+	if errors.As(err, &wrappedErr) {
+		statusCode = wrappedErr.StatusCode
+	} else if errors.As(err, &wrappedErrPtr) && wrappedErrPtr != nil {
+		statusCode = wrappedErrPtr.StatusCode
+	}
+	return statusCode != nil && *statusCode == http.StatusNotFound
+}
@@ -0,0 +1,212 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzureDevOpsClient implements the parts of the Azure DevOps Git client used by the provider.
+type fakeAzureDevOpsClient struct {
+	git.Client
+	repos []git.GitRepository
+	// branches are keyed by repository ID.
+	branches map[string][]git.GitBranchStats
+	// branchErrors are keyed by repository ID.
+	branchErrors map[string]error
+	// paths are keyed by repository name and branch, eg "repo@main".
+	paths map[string][]string
+	// pointerErrors makes GetItem return a *WrappedError, as the client does for most responses, instead of a
+	// WrappedError.
+	pointerErrors bool
+}
+
+func (f *fakeAzureDevOpsClient) GetRepositories(_ context.Context, args git.GetRepositoriesArgs) (*[]git.GitRepository, error) {
+	if *args.Project != "myproject" {
+		return nil, fmt.Errorf("project %s not found", *args.Project)
+	}
+	return &f.repos, nil
+}
+
+func (f *fakeAzureDevOpsClient) GetBranch(_ context.Context, args git.GetBranchArgs) (*git.GitBranchStats, error) {
+	if err := f.branchErrors[*args.RepositoryId]; err != nil {
+		return nil, err
+	}
+	for _, branch := range f.branches[*args.RepositoryId] {
+		if *branch.Name == *args.Name {
+			return &branch, nil
+		}
+	}
+	return nil, fmt.Errorf("branch %s not found", *args.Name)
+}
+
+func (f *fakeAzureDevOpsClient) GetBranches(_ context.Context, args git.GetBranchesArgs) (*[]git.GitBranchStats, error) {
+	if err := f.branchErrors[*args.RepositoryId]; err != nil {
+		return nil, err
+	}
+	branches := f.branches[*args.RepositoryId]
+	return &branches, nil
+}
+
+func (f *fakeAzureDevOpsClient) GetItem(_ context.Context, args git.GetItemArgs) (*git.GitItem, error) {
+	for _, path := range f.paths[*args.RepositoryId+"@"+*args.VersionDescriptor.Version] {
+		if path == *args.Path {
+			return &git.GitItem{Path: args.Path}, nil
+		}
+	}
+	message := "TF401174: The item could not be found in the repository"
+	statusCode := http.StatusNotFound
+	if f.pointerErrors {
+		return nil, &azuredevops.WrappedError{Message: &message, StatusCode: &statusCode}
+	}
+	return nil, azuredevops.WrappedError{Message: &message, StatusCode: &statusCode}
+}
+
+func azureDevOpsBranch(name, sha string) git.GitBranchStats {
+	return git.GitBranchStats{Name: &name, Commit: &git.GitCommitRef{CommitId: &sha}}
+}
+
+func newFakeAzureDevOpsClient() *fakeAzureDevOpsClient {
+	appID := uuid.MustParse("7b6d3a8c-0f1a-4c2e-9d7b-1a2b3c4d5e6f")
+	brokenID := uuid.MustParse("3f2e1d0c-9b8a-4765-8432-10fedcba9876")
+	emptyID := uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d")
+	app, broken, empty := "app", "broken", "empty"
+	mainBranch := "refs/heads/main"
+	return &fakeAzureDevOpsClient{
+		repos: []git.GitRepository{
+			{
+				Id:            &appID,
+				Name:          &app,
+				DefaultBranch: &mainBranch,
+				RemoteUrl:     strPtr("https://myorg@dev.azure.com/myorg/myproject/_git/app"),
+				SshUrl:        strPtr("git@ssh.dev.azure.com:v3/myorg/myproject/app"),
+			},
+			{
+				Id:            &brokenID,
+				Name:          &broken,
+				DefaultBranch: &mainBranch,
+				RemoteUrl:     strPtr("https://myorg@dev.azure.com/myorg/myproject/_git/broken"),
+				SshUrl:        strPtr("git@ssh.dev.azure.com:v3/myorg/myproject/broken"),
+			},
+			{
+				Id:        &emptyID,
+				Name:      &empty,
+				RemoteUrl: strPtr("https://myorg@dev.azure.com/myorg/myproject/_git/empty"),
+				SshUrl:    strPtr("git@ssh.dev.azure.com:v3/myorg/myproject/empty"),
+			},
+		},
+		branches: map[string][]git.GitBranchStats{
+			appID.String(): {
+				azureDevOpsBranch("main", "2e4b1f1ce9a4d5a2d8c4b3a2f1e0d9c8b7a69584"),
+				azureDevOpsBranch("feature", "9c1d8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c"),
+			},
+		},
+		branchErrors: map[string]error{
+			brokenID.String(): fmt.Errorf("TF401019: The Git repository does not exist or you do not have permissions"),
+		},
+		paths: map[string][]string{
+			"app@main": {"deploy"},
+		},
+	}
+}
+
+func strPtr(value string) *string {
+	return &value
+}
+
+func TestAzureDevOpsListRepos(t *testing.T) {
+	cases := []struct {
+		name, proto string
+		allBranches bool
+		hasError    bool
+		repos       []*Repository
+	}{
+		{
+			name: "blank protocol",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@ssh.dev.azure.com:v3/myorg/myproject/app", Branch: "main", SHA: "2e4b1f1ce9a4d5a2d8c4b3a2f1e0d9c8b7a69584"},
+			},
+		},
+		{
+			name:  "https protocol",
+			proto: "https",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "https://myorg@dev.azure.com/myorg/myproject/_git/app", Branch: "main", SHA: "2e4b1f1ce9a4d5a2d8c4b3a2f1e0d9c8b7a69584"},
+			},
+		},
+		{
+			name:     "other protocol",
+			proto:    "other",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@ssh.dev.azure.com:v3/myorg/myproject/app", Branch: "main", SHA: "2e4b1f1ce9a4d5a2d8c4b3a2f1e0d9c8b7a69584"},
+				{Organization: "myorg", Repository: "app", URL: "git@ssh.dev.azure.com:v3/myorg/myproject/app", Branch: "feature", SHA: "9c1d8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := &AzureDevOpsProvider{client: newFakeAzureDevOpsClient(), organization: "myorg", project: "myproject", allBranches: c.allBranches}
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+				return
+			}
+			// The repository whose branches can't be listed is skipped, and its error returned.
+			var repoErrs *RepositoryErrors
+			if assert.ErrorAs(t, err, &repoErrs) {
+				assert.EqualError(t, repoErrs, "error listing branches for myproject/broken: TF401019: The Git repository does not exist or you do not have permissions")
+			}
+			assert.Equal(t, c.repos, repos)
+		})
+	}
+}
+
+func TestAzureDevOpsListReposError(t *testing.T) {
+	provider := &AzureDevOpsProvider{client: newFakeAzureDevOpsClient(), organization: "myorg", project: "other"}
+	_, err := provider.ListRepos(context.Background(), "", true)
+	assert.EqualError(t, err, "error listing repositories for myorg/other: project other not found")
+}
+
+func TestAzureDevOpsHasPath(t *testing.T) {
+	provider := &AzureDevOpsProvider{client: newFakeAzureDevOpsClient(), organization: "myorg", project: "myproject"}
+	repo := &Repository{
+		Organization: "myorg",
+		Repository:   "app",
+		Branch:       "main",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "deploy")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAzureDevOpsHasPathPointerError(t *testing.T) {
+	client := newFakeAzureDevOpsClient()
+	client.pointerErrors = true
+	provider := &AzureDevOpsProvider{client: client, organization: "myorg", project: "myproject"}
+	repo := &Repository{
+		Organization: "myorg",
+		Repository:   "app",
+		Branch:       "main",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,252 @@
+package scm_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
+)
+
+// bitbucketServerPageLimit is the number of items requested per page, the default maximum of Bitbucket Server.
+const bitbucketServerPageLimit = 100
+
+type BitbucketServerProvider struct {
+	client      *http.Client
+	url         string
+	projectKey  string
+	username    string
+	password    string
+	token       string
+	allBranches bool
+}
+
+var _ SCMProviderService = &BitbucketServerProvider{}
+
+// bitbucketServerPage is the envelope of the paged responses of the Bitbucket Server REST API.
+type bitbucketServerPage struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+type bitbucketServerRepo struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []struct {
+			Href string `json:"href"`
+			Name string `json:"name"`
+		} `json:"clone"`
+	} `json:"links"`
+}
+
+type bitbucketServerBranch struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
+
+// NewBitbucketServerProvider returns a provider listing the repositories of a Bitbucket Server project. Requests are
+// authenticated with the token as a bearer token if it is set, with basic auth if username is set, or else made
+// anonymously.
+func NewBitbucketServerProvider(ctx context.Context, projectKey, url, username, password, token string, allBranches bool) (*BitbucketServerProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("bitbucket server API URL is required")
+	}
+	if projectKey == "" {
+		return nil, fmt.Errorf("bitbucket server project is required")
+	}
+	if username != "" && token != "" {
+		return nil, fmt.Errorf("only one of username and token may be set")
+	}
+	return &BitbucketServerProvider{
+		client:      &http.Client{Transport: ratelimit.NewTransport(nil)},
+		url:         strings.TrimSuffix(url, "/"),
+		projectKey:  projectKey,
+		username:    username,
+		password:    password,
+		token:       token,
+		allBranches: allBranches,
+	}, nil
+}
+
+func (b *BitbucketServerProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	// Bitbucket Server names the HTTPS clone link "http", regardless of the scheme.
+	var linkName string
+	switch cloneProtocol {
+	// Default to SSH if unspecified (i.e. if "").
+	case "", "ssh":
+		linkName = "ssh"
+	case "https":
+		linkName = "http"
+	default:
+		return nil, fmt.Errorf("unknown clone protocol for Bitbucket Server %v", cloneProtocol)
+	}
+
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	err := b.listPaged(ctx, fmt.Sprintf("/projects/%s/repos", url.PathEscape(b.projectKey)), func(values json.RawMessage) error {
+		var bitbucketRepos []bitbucketServerRepo
+		if err := json.Unmarshal(values, &bitbucketRepos); err != nil {
+			return err
+		}
+		for _, bitbucketRepo := range bitbucketRepos {
+			var cloneURL string
+			for _, link := range bitbucketRepo.Links.Clone {
+				if link.Name == linkName {
+					cloneURL = link.Href
+				}
+			}
+			if cloneURL == "" {
+				if err := repoErrs.add(fmt.Errorf("repository %s/%s has no %s clone URL", bitbucketRepo.Project.Key, bitbucketRepo.Slug, linkName)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			branches, err := b.listBranches(ctx, bitbucketRepo)
+			if err != nil {
+				if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %w", bitbucketRepo.Project.Key, bitbucketRepo.Slug, err)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, branch := range branches {
+				repos = append(repos, &Repository{
+					Organization: bitbucketRepo.Project.Key,
+					Repository:   bitbucketRepo.Slug,
+					URL:          cloneURL,
+					Branch:       branch.DisplayID,
+					SHA:          branch.LatestCommit,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories for %s: %w", b.projectKey, err)
+	}
+	return repos, repoErrs.err()
+}
+
+func (b *BitbucketServerProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	query := url.Values{}
+	query.Set("at", repo.Branch)
+	query.Set("limit", "1")
+	resp, err := b.get(ctx, fmt.Sprintf("/projects/%s/repos/%s/browse/%s", url.PathEscape(repo.Organization), url.PathEscape(repo.Repository), escapePath(path)), query)
+	// 404s are not an error here, just a normal false.
+	if resp != nil && resp.statusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *BitbucketServerProvider) listBranches(ctx context.Context, repo bitbucketServerRepo) ([]bitbucketServerBranch, error) {
+	branchesPath := fmt.Sprintf("/projects/%s/repos/%s/branches", url.PathEscape(repo.Project.Key), url.PathEscape(repo.Slug))
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	if !b.allBranches {
+		var defaultBranch bitbucketServerBranch
+		resp, err := b.get(ctx, branchesPath+"/default", nil)
+		// Empty repositories have no default branch, and so nothing to generate.
+		if resp != nil && resp.statusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(resp.body, &defaultBranch); err != nil {
+			return nil, fmt.Errorf("error decoding response: %v", err)
+		}
+		return []bitbucketServerBranch{defaultBranch}, nil
+	}
+	// Otherwise, scrape the branches API.
+	branches := []bitbucketServerBranch{}
+	err := b.listPaged(ctx, branchesPath, func(values json.RawMessage) error {
+		var page []bitbucketServerBranch
+		if err := json.Unmarshal(values, &page); err != nil {
+			return err
+		}
+		branches = append(branches, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// listPaged calls handle with the values of every page of the paged API at path.
+func (b *BitbucketServerProvider) listPaged(ctx context.Context, path string, handle func(json.RawMessage) error) error {
+	start := 0
+	for {
+		query := url.Values{}
+		query.Set("start", strconv.Itoa(start))
+		query.Set("limit", strconv.Itoa(bitbucketServerPageLimit))
+		resp, err := b.get(ctx, path, query)
+		if err != nil {
+			return err
+		}
+		var page bitbucketServerPage
+		if err := json.Unmarshal(resp.body, &page); err != nil {
+			return fmt.Errorf("error decoding response: %v", err)
+		}
+		if err := handle(page.Values); err != nil {
+			return fmt.Errorf("error decoding response: %v", err)
+		}
+		if page.IsLastPage {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// bitbucketServerResponse is a response of the Bitbucket Server REST API, with its body read.
+type bitbucketServerResponse struct {
+	statusCode int
+	body       []byte
+}
+
+// get makes a GET request to path of the REST API. An error is returned along with the response if the status is
+// not 200.
+func (b *BitbucketServerProvider) get(ctx context.Context, path string, query url.Values) (*bitbucketServerResponse, error) {
+	reqURL := b.url + "/rest/api/1.0" + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	} else if b.username != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+
+	httpResp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &bitbucketServerResponse{statusCode: httpResp.StatusCode, body: body}
+	if httpResp.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("unexpected status %d: %s", httpResp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,219 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bitbucketServerMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			t.Errorf("unexpected authorization for %s", r.URL.String())
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/rest/api/1.0/projects/PROJECT/repos?limit=100&start=0":
+			fmt.Fprint(w, `{
+	"size": 1,
+	"limit": 1,
+	"isLastPage": false,
+	"nextPageStart": 1,
+	"values": [
+		{
+			"slug": "repo-1",
+			"project": {"key": "PROJECT"},
+			"links": {"clone": [
+				{"href": "https://bitbucket.example.com/scm/project/repo-1.git", "name": "http"},
+				{"href": "ssh://git@bitbucket.example.com:7999/project/repo-1.git", "name": "ssh"}
+			]}
+		}
+	]
+}`)
+		case "/rest/api/1.0/projects/PROJECT/repos?limit=100&start=1":
+			fmt.Fprint(w, `{
+	"size": 2,
+	"limit": 100,
+	"isLastPage": true,
+	"values": [
+		{
+			"slug": "repo-2",
+			"project": {"key": "PROJECT"},
+			"links": {"clone": [
+				{"href": "https://bitbucket.example.com/scm/project/repo-2.git", "name": "http"},
+				{"href": "ssh://git@bitbucket.example.com:7999/project/repo-2.git", "name": "ssh"}
+			]}
+		},
+		{
+			"slug": "empty",
+			"project": {"key": "PROJECT"},
+			"links": {"clone": [
+				{"href": "https://bitbucket.example.com/scm/project/empty.git", "name": "http"},
+				{"href": "ssh://git@bitbucket.example.com:7999/project/empty.git", "name": "ssh"}
+			]}
+		}
+	]
+}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-1/branches/default?":
+			fmt.Fprint(w, `{"id": "refs/heads/main", "displayId": "main", "latestCommit": "8d51122def5632836d1cb1026e879069e10a1e13"}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-2/branches/default?":
+			fmt.Fprint(w, `{"id": "refs/heads/master", "displayId": "master", "latestCommit": "1e8c8ea3d3bd5d1ab1e6c8ba8e9b5e1a07d1c6ff"}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/empty/branches/default?":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"message": "Repository PROJECT/empty has no default branch"}]}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-1/branches?limit=100&start=0":
+			fmt.Fprint(w, `{
+	"isLastPage": true,
+	"values": [
+		{"id": "refs/heads/main", "displayId": "main", "latestCommit": "8d51122def5632836d1cb1026e879069e10a1e13"},
+		{"id": "refs/heads/feature", "displayId": "feature", "latestCommit": "0a5bd4cb0d2dc0a54b16ef3f0c7dd1f16e4e26a6"}
+	]
+}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-2/branches?limit=100&start=0":
+			fmt.Fprint(w, `{
+	"isLastPage": true,
+	"values": [
+		{"id": "refs/heads/master", "displayId": "master", "latestCommit": "1e8c8ea3d3bd5d1ab1e6c8ba8e9b5e1a07d1c6ff"}
+	]
+}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/empty/branches?limit=100&start=0":
+			fmt.Fprint(w, `{"isLastPage": true, "values": []}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-1/browse/pkg?at=main&limit=1":
+			fmt.Fprint(w, `{"path": {"name": "pkg"}, "children": {"values": []}}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-1/browse/docs/release #1?at=main&limit=1":
+			fmt.Fprint(w, `{"path": {"name": "release #1"}, "children": {"values": []}}`)
+		case "/rest/api/1.0/projects/PROJECT/repos/repo-1/browse/notathing?at=main&limit=1":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": [{"message": "The path \"notathing\" does not exist at revision \"main\""}]}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestBitbucketServerListRepos(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(bitbucketServerMockHandler(t)))
+	defer ts.Close()
+
+	cases := []struct {
+		name, proto string
+		allBranches bool
+		hasError    bool
+		repos       []*Repository
+	}{
+		{
+			name: "blank protocol",
+			repos: []*Repository{
+				{Organization: "PROJECT", Repository: "repo-1", URL: "ssh://git@bitbucket.example.com:7999/project/repo-1.git", Branch: "main", SHA: "8d51122def5632836d1cb1026e879069e10a1e13"},
+				{Organization: "PROJECT", Repository: "repo-2", URL: "ssh://git@bitbucket.example.com:7999/project/repo-2.git", Branch: "master", SHA: "1e8c8ea3d3bd5d1ab1e6c8ba8e9b5e1a07d1c6ff"},
+			},
+		},
+		{
+			name:  "https protocol",
+			proto: "https",
+			repos: []*Repository{
+				{Organization: "PROJECT", Repository: "repo-1", URL: "https://bitbucket.example.com/scm/project/repo-1.git", Branch: "main", SHA: "8d51122def5632836d1cb1026e879069e10a1e13"},
+				{Organization: "PROJECT", Repository: "repo-2", URL: "https://bitbucket.example.com/scm/project/repo-2.git", Branch: "master", SHA: "1e8c8ea3d3bd5d1ab1e6c8ba8e9b5e1a07d1c6ff"},
+			},
+		},
+		{
+			name:     "other protocol",
+			proto:    "other",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "PROJECT", Repository: "repo-1", URL: "ssh://git@bitbucket.example.com:7999/project/repo-1.git", Branch: "main", SHA: "8d51122def5632836d1cb1026e879069e10a1e13"},
+				{Organization: "PROJECT", Repository: "repo-1", URL: "ssh://git@bitbucket.example.com:7999/project/repo-1.git", Branch: "feature", SHA: "0a5bd4cb0d2dc0a54b16ef3f0c7dd1f16e4e26a6"},
+				{Organization: "PROJECT", Repository: "repo-2", URL: "ssh://git@bitbucket.example.com:7999/project/repo-2.git", Branch: "master", SHA: "1e8c8ea3d3bd5d1ab1e6c8ba8e9b5e1a07d1c6ff"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider, err := NewBitbucketServerProvider(context.Background(), "PROJECT", ts.URL+"/", "", "", "access-token", c.allBranches)
+			assert.NoError(t, err)
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.repos, repos)
+			}
+		})
+	}
+}
+
+func TestBitbucketServerListReposBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "jdoe", username)
+		assert.Equal(t, "password", password)
+		fmt.Fprint(w, `{"isLastPage": true, "values": []}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewBitbucketServerProvider(context.Background(), "PROJECT", ts.URL, "jdoe", "password", "", false)
+	assert.NoError(t, err)
+	repos, err := provider.ListRepos(context.Background(), "", true)
+	assert.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestBitbucketServerListReposError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"errors": [{"message": "Authentication failed"}]}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewBitbucketServerProvider(context.Background(), "PROJECT", ts.URL, "", "", "", false)
+	assert.NoError(t, err)
+	_, err = provider.ListRepos(context.Background(), "", true)
+	assert.EqualError(t, err, `error listing repositories for PROJECT: unexpected status 401: {"errors": [{"message": "Authentication failed"}]}`)
+}
+
+func TestBitbucketServerHasPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(bitbucketServerMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewBitbucketServerProvider(context.Background(), "PROJECT", ts.URL, "", "", "access-token", false)
+	assert.NoError(t, err)
+	repo := &Repository{
+		Organization: "PROJECT",
+		Repository:   "repo-1",
+		Branch:       "main",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "pkg/")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "/docs/release #1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewBitbucketServerProviderValidation(t *testing.T) {
+	_, err := NewBitbucketServerProvider(context.Background(), "PROJECT", "", "", "", "", false)
+	assert.Error(t, err)
+	_, err = NewBitbucketServerProvider(context.Background(), "PROJECT", "https://bitbucket.example.com", "jdoe", "password", "access-token", false)
+	assert.Error(t, err)
+	_, err = NewBitbucketServerProvider(context.Background(), "", "https://bitbucket.example.com", "", "", "access-token", false)
+	assert.EqualError(t, err, "bitbucket server project is required")
+}
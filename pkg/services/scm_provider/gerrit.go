@@ -0,0 +1,183 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/applicationset/pkg/services/gerrit"
+)
+
+// gerritPageLimit is the number of items requested per page.
+const gerritPageLimit = 100
+
+type GerritProvider struct {
+	client      *gerrit.Client
+	prefix      string
+	allBranches bool
+}
+
+var _ SCMProviderService = &GerritProvider{}
+
+// gerritProject is the subset of the Gerrit ProjectInfo entity used by the provider.
+type gerritProject struct {
+	MoreProjects bool `json:"_more_projects"`
+}
+
+// gerritBranch is the subset of the Gerrit BranchInfo entity used by the provider.
+type gerritBranch struct {
+	Ref      string `json:"ref"`
+	Revision string `json:"revision"`
+}
+
+// NewGerritProvider returns a provider listing the projects of a Gerrit server whose names start with prefix. If
+// username is empty, requests are made anonymously; otherwise password must be the user's Gerrit HTTP password.
+func NewGerritProvider(ctx context.Context, url, prefix, username, password string, allBranches bool) (*GerritProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("gerrit API URL is required")
+	}
+	return &GerritProvider{
+		client:      gerrit.NewClient(&http.Client{}, url, username, password),
+		prefix:      prefix,
+		allBranches: allBranches,
+	}, nil
+}
+
+func (g *GerritProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	// Gerrit serves Git over HTTPS at the URL of the project, while its SSH daemon is configured separately.
+	switch cloneProtocol {
+	case "", "https":
+	default:
+		return nil, fmt.Errorf("unknown clone protocol for Gerrit %v", cloneProtocol)
+	}
+
+	projects, err := g.listProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing projects for %q: %v", g.prefix, err)
+	}
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	for _, project := range projects {
+		branches, err := g.listBranches(ctx, project)
+		if err != nil {
+			if err := repoErrs.add(fmt.Errorf("error listing branches for %s: %v", project, err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		organization, name := pathpkg.Split(project)
+		for _, branch := range branches {
+			repos = append(repos, &Repository{
+				Organization: strings.TrimSuffix(organization, "/"),
+				Repository:   name,
+				URL:          g.client.URL() + "/" + project,
+				Branch:       strings.TrimPrefix(branch.Ref, "refs/heads/"),
+				SHA:          branch.Revision,
+			})
+		}
+	}
+	return repos, repoErrs.err()
+}
+
+// RepoHasPath returns true if the path is a file of the branch. Gerrit has no API to look up directories.
+func (g *GerritProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	project := repo.Repository
+	if repo.Organization != "" {
+		project = repo.Organization + "/" + repo.Repository
+	}
+	err := g.client.Get(ctx, fmt.Sprintf("/projects/%s/branches/%s/files/%s/content", url.PathEscape(project), url.PathEscape(repo.Branch), url.PathEscape(strings.Trim(path, "/"))), nil, nil)
+	// 404s are not an error here, just a normal false.
+	if gerrit.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// listProjects lists the names of the active code projects whose names start with the prefix, sorted.
+func (g *GerritProvider) listProjects(ctx context.Context) ([]string, error) {
+	names := []string{}
+	start := 0
+	for {
+		query := url.Values{}
+		query.Set("type", "CODE")
+		query.Set("s", "ACTIVE")
+		query.Set("n", strconv.Itoa(gerritPageLimit))
+		query.Set("S", strconv.Itoa(start))
+		if g.prefix != "" {
+			query.Set("p", g.prefix)
+		}
+		var projects map[string]gerritProject
+		if err := g.client.Get(ctx, "/projects/", query, &projects); err != nil {
+			return nil, err
+		}
+		more := false
+		for name, project := range projects {
+			names = append(names, name)
+			// Gerrit flags the last project of a page when more results are available.
+			more = more || project.MoreProjects
+		}
+		if !more {
+			break
+		}
+		start += len(projects)
+	}
+	// Projects are returned as a map, so restore their order.
+	sort.Strings(names)
+	return names, nil
+}
+
+func (g *GerritProvider) listBranches(ctx context.Context, project string) ([]gerritBranch, error) {
+	projectPath := "/projects/" + url.PathEscape(project)
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	if !g.allBranches {
+		var head string
+		if err := g.client.Get(ctx, projectPath+"/HEAD", nil, &head); err != nil {
+			return nil, err
+		}
+		// Projects which only hold metadata, eg All-Projects, have a HEAD which isn't a branch.
+		if !strings.HasPrefix(head, "refs/heads/") {
+			return nil, nil
+		}
+		var branch gerritBranch
+		err := g.client.Get(ctx, projectPath+"/branches/"+url.PathEscape(head), nil, &branch)
+		// The branch of HEAD doesn't exist until something is pushed to it.
+		if gerrit.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []gerritBranch{branch}, nil
+	}
+	// Otherwise, scrape the branches API, skipping the symbolic HEAD and other refs which aren't branches.
+	branches := []gerritBranch{}
+	start := 0
+	for {
+		query := url.Values{}
+		query.Set("n", strconv.Itoa(gerritPageLimit))
+		query.Set("S", strconv.Itoa(start))
+		var page []gerritBranch
+		if err := g.client.Get(ctx, projectPath+"/branches/", query, &page); err != nil {
+			return nil, err
+		}
+		for _, branch := range page {
+			if strings.HasPrefix(branch.Ref, "refs/heads/") {
+				branches = append(branches, branch)
+			}
+		}
+		if len(page) < gerritPageLimit {
+			break
+		}
+		start += len(page)
+	}
+	return branches, nil
+}
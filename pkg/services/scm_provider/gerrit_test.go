@@ -0,0 +1,202 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gerritMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "jdoe" || password != "http-password" {
+			t.Errorf("unexpected authorization for %s", r.RequestURI)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.RequestURI {
+		case "/a/projects/?S=0&n=100&p=platform%2F&s=ACTIVE&type=CODE":
+			fmt.Fprint(w, `)]}'
+{
+	"platform/web": {"id": "platform%2Fweb", "state": "ACTIVE"},
+	"platform/api": {"id": "platform%2Fapi", "state": "ACTIVE", "_more_projects": true}
+}`)
+		case "/a/projects/?S=2&n=100&p=platform%2F&s=ACTIVE&type=CODE":
+			fmt.Fprint(w, `)]}'
+{
+	"platform/new": {"id": "platform%2Fnew", "state": "ACTIVE"}
+}`)
+		case "/a/projects/platform%2Fapi/HEAD":
+			fmt.Fprint(w, ")]}'\n\"refs/heads/main\"")
+		case "/a/projects/platform%2Fweb/HEAD":
+			fmt.Fprint(w, ")]}'\n\"refs/heads/master\"")
+		case "/a/projects/platform%2Fnew/HEAD":
+			fmt.Fprint(w, ")]}'\n\"refs/heads/master\"")
+		case "/a/projects/platform%2Fapi/branches/refs%2Fheads%2Fmain":
+			fmt.Fprint(w, `)]}'
+{"ref": "refs/heads/main", "revision": "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"}`)
+		case "/a/projects/platform%2Fweb/branches/refs%2Fheads%2Fmaster":
+			fmt.Fprint(w, `)]}'
+{"ref": "refs/heads/master", "revision": "089d92cbf9ff857a39e6feccd32798ca700fb958"}`)
+		case "/a/projects/platform%2Fnew/branches/refs%2Fheads%2Fmaster":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Not found: refs/heads/master")
+		case "/a/projects/platform%2Fapi/branches/?S=0&n=100":
+			fmt.Fprint(w, `)]}'
+[
+	{"ref": "HEAD", "revision": "main"},
+	{"ref": "refs/heads/feature", "revision": "b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0"},
+	{"ref": "refs/heads/main", "revision": "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"},
+	{"ref": "refs/meta/config", "revision": "0f1e2d3c4b5a69788796a5b4c3d2e1f0a9b8c7d6"}
+]`)
+		case "/a/projects/platform%2Fweb/branches/?S=0&n=100":
+			fmt.Fprint(w, `)]}'
+[
+	{"ref": "HEAD", "revision": "master"},
+	{"ref": "refs/heads/master", "revision": "089d92cbf9ff857a39e6feccd32798ca700fb958"}
+]`)
+		case "/a/projects/platform%2Fnew/branches/?S=0&n=100":
+			fmt.Fprint(w, `)]}'
+[
+	{"ref": "HEAD", "revision": "master"}
+]`)
+		case "/a/projects/platform%2Fweb/branches/master/files/deploy%2Fapp.yaml/content":
+			fmt.Fprint(w, "a2luZDogRGVwbG95bWVudAo=")
+		case "/a/projects/platform%2Fweb/branches/master/files/notathing/content":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Not found: notathing")
+		default:
+			t.Errorf("unexpected request: %s", r.RequestURI)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGerritListRepos(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gerritMockHandler(t)))
+	defer ts.Close()
+
+	cases := []struct {
+		name, proto string
+		allBranches bool
+		hasError    bool
+		repos       []*Repository
+	}{
+		{
+			name: "blank protocol",
+			repos: []*Repository{
+				{Organization: "platform", Repository: "api", URL: ts.URL + "/platform/api", Branch: "main", SHA: "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"},
+				{Organization: "platform", Repository: "web", URL: ts.URL + "/platform/web", Branch: "master", SHA: "089d92cbf9ff857a39e6feccd32798ca700fb958"},
+			},
+		},
+		{
+			name:  "https protocol",
+			proto: "https",
+			repos: []*Repository{
+				{Organization: "platform", Repository: "api", URL: ts.URL + "/platform/api", Branch: "main", SHA: "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"},
+				{Organization: "platform", Repository: "web", URL: ts.URL + "/platform/web", Branch: "master", SHA: "089d92cbf9ff857a39e6feccd32798ca700fb958"},
+			},
+		},
+		{
+			name:     "ssh protocol",
+			proto:    "ssh",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "platform", Repository: "api", URL: ts.URL + "/platform/api", Branch: "feature", SHA: "b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0"},
+				{Organization: "platform", Repository: "api", URL: ts.URL + "/platform/api", Branch: "main", SHA: "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"},
+				{Organization: "platform", Repository: "web", URL: ts.URL + "/platform/web", Branch: "master", SHA: "089d92cbf9ff857a39e6feccd32798ca700fb958"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider, err := NewGerritProvider(context.Background(), ts.URL+"/", "platform/", "jdoe", "http-password", c.allBranches)
+			assert.NoError(t, err)
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.repos, repos)
+			}
+		})
+	}
+}
+
+func TestGerritListReposAnonymous(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.False(t, ok)
+		switch r.URL.Path {
+		case "/projects/":
+			fmt.Fprint(w, ")]}'\n{\"All-Projects\": {}, \"app\": {}}")
+		case "/projects/All-Projects/HEAD":
+			fmt.Fprint(w, ")]}'\n\"refs/meta/config\"")
+		case "/projects/app/HEAD":
+			fmt.Fprint(w, ")]}'\n\"refs/heads/main\"")
+		case "/projects/app/branches/refs/heads/main":
+			fmt.Fprint(w, ")]}'\n{\"ref\": \"refs/heads/main\", \"revision\": \"4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b\"}")
+		default:
+			t.Errorf("unexpected request: %s", r.RequestURI)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	provider, err := NewGerritProvider(context.Background(), ts.URL, "", "", "", false)
+	assert.NoError(t, err)
+	repos, err := provider.ListRepos(context.Background(), "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Repository{
+		{Organization: "", Repository: "app", URL: ts.URL + "/app", Branch: "main", SHA: "4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b"},
+	}, repos)
+}
+
+func TestGerritListReposError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "Unauthorized")
+	}))
+	defer ts.Close()
+
+	provider, err := NewGerritProvider(context.Background(), ts.URL, "platform/", "jdoe", "wrong", false)
+	assert.NoError(t, err)
+	_, err = provider.ListRepos(context.Background(), "", true)
+	assert.EqualError(t, err, `error listing projects for "platform/": unexpected status 401: Unauthorized`)
+}
+
+func TestGerritHasPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gerritMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewGerritProvider(context.Background(), ts.URL, "platform/", "jdoe", "http-password", false)
+	assert.NoError(t, err)
+	repo := &Repository{
+		Organization: "platform",
+		Repository:   "web",
+		Branch:       "master",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "deploy/app.yaml")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewGerritProviderRequiresURL(t *testing.T) {
+	_, err := NewGerritProvider(context.Background(), "", "", "", "", false)
+	assert.Error(t, err)
+}
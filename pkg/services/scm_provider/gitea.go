@@ -0,0 +1,161 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+	log "github.com/sirupsen/logrus"
+)
+
+// giteaPageSize is the number of items requested per page, the maximum allowed by the Gitea client.
+const giteaPageSize = 50
+
+type GiteaProvider struct {
+	client      *gitea.Client
+	owner       string
+	allBranches bool
+}
+
+var _ SCMProviderService = &GiteaProvider{}
+
+func NewGiteaProvider(ctx context.Context, owner, token, url string, allBranches bool) (*GiteaProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("gitea API URL is required")
+	}
+	// The context only applies to the version check of the client, the other requests use the context of each call.
+	client, err := gitea.NewClient(url, gitea.SetToken(token), gitea.SetContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &GiteaProvider{client: client, owner: owner, allBranches: allBranches}, nil
+}
+
+func (g *GiteaProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	g.client.SetContext(ctx)
+	giteaRepos, err := g.listOwnerRepos()
+	if err != nil {
+		return nil, fmt.Errorf("error listing repositories for %s: %v", g.owner, err)
+	}
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	for _, giteaRepo := range giteaRepos {
+		// Empty repositories have no branches, and so nothing to generate.
+		if giteaRepo.Empty {
+			continue
+		}
+		var url string
+		switch cloneProtocol {
+		// Default to SSH if unspecified (i.e. if "").
+		case "", "ssh":
+			url = giteaRepo.SSHURL
+		case "https":
+			url = giteaRepo.CloneURL
+		default:
+			return nil, fmt.Errorf("unknown clone protocol for Gitea %v", cloneProtocol)
+		}
+
+		branches, err := g.listBranches(giteaRepo)
+		if err != nil {
+			if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %v", giteaRepo.Owner.UserName, giteaRepo.Name, err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		topics, _, err := g.client.ListRepoTopics(giteaRepo.Owner.UserName, giteaRepo.Name, gitea.ListRepoTopicsOptions{
+			ListOptions: gitea.ListOptions{PageSize: giteaPageSize},
+		})
+		// Topics are only used by label filters, so failing to read them doesn't fail the repository.
+		if err != nil {
+			log.Warnf("error listing topics for %s/%s, using no labels: %v", giteaRepo.Owner.UserName, giteaRepo.Name, err)
+			topics = []string{}
+		}
+
+		for _, branch := range branches {
+			repos = append(repos, &Repository{
+				Organization: giteaRepo.Owner.UserName,
+				Repository:   giteaRepo.Name,
+				URL:          url,
+				Branch:       branch.Name,
+				SHA:          branch.Commit.ID,
+				Labels:       topics,
+			})
+		}
+	}
+	return repos, repoErrs.err()
+}
+
+func (g *GiteaProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	g.client.SetContext(ctx)
+	_, resp, err := g.client.GetContents(repo.Organization, repo.Repository, repo.Branch, path)
+	// 404s are not an error here, just a normal false.
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	// GetContents fails to decode the listing of a directory, which still means the path exists.
+	if err != nil && !(resp != nil && resp.StatusCode == http.StatusOK) {
+		return false, err
+	}
+	return true, nil
+}
+
+// listOwnerRepos lists the repositories of the owner, which is either an organization or a user.
+func (g *GiteaProvider) listOwnerRepos() ([]*gitea.Repository, error) {
+	listPage := func(opt gitea.ListOptions) ([]*gitea.Repository, *gitea.Response, error) {
+		return g.client.ListOrgRepos(g.owner, gitea.ListOrgReposOptions{ListOptions: opt})
+	}
+	_, resp, err := g.client.GetOrg(g.owner)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// There is no organization of that name, so it must be a user.
+		listPage = func(opt gitea.ListOptions) ([]*gitea.Repository, *gitea.Response, error) {
+			return g.client.ListUserRepos(g.owner, gitea.ListReposOptions{ListOptions: opt})
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	repos := []*gitea.Repository{}
+	opt := gitea.ListOptions{Page: 1, PageSize: giteaPageSize}
+	for {
+		giteaRepos, _, err := listPage(opt)
+		if err != nil {
+			return nil, err
+		}
+		// Gitea may cap the page size below the requested one, so stop at the first empty page.
+		if len(giteaRepos) == 0 {
+			break
+		}
+		repos = append(repos, giteaRepos...)
+		opt.Page++
+	}
+	return repos, nil
+}
+
+func (g *GiteaProvider) listBranches(repo *gitea.Repository) ([]*gitea.Branch, error) {
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	if !g.allBranches {
+		branch, _, err := g.client.GetRepoBranch(repo.Owner.UserName, repo.Name, repo.DefaultBranch)
+		if err != nil {
+			return nil, err
+		}
+		return []*gitea.Branch{branch}, nil
+	}
+	// Otherwise, scrape the ListRepoBranches API.
+	branches := []*gitea.Branch{}
+	opt := gitea.ListRepoBranchesOptions{
+		ListOptions: gitea.ListOptions{Page: 1, PageSize: giteaPageSize},
+	}
+	for {
+		giteaBranches, _, err := g.client.ListRepoBranches(repo.Owner.UserName, repo.Name, opt)
+		if err != nil {
+			return nil, err
+		}
+		if len(giteaBranches) == 0 {
+			break
+		}
+		branches = append(branches, giteaBranches...)
+		opt.Page++
+	}
+	return branches, nil
+}
@@ -0,0 +1,201 @@
+package scm_provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func giteaMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/version" && r.Header.Get("Authorization") != "token access-token" {
+			t.Errorf("unexpected authorization for %s", r.URL.String())
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/api/v1/version?":
+			fmt.Fprint(w, `{"version": "1.15.6"}`)
+		case "/api/v1/orgs/myorg?":
+			fmt.Fprint(w, `{"id": 2, "username": "myorg"}`)
+		case "/api/v1/orgs/jdoe?":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "GetOrgByName"}`)
+		case "/api/v1/orgs/myorg/repos?limit=50&page=1":
+			fmt.Fprint(w, `[
+	{
+		"id": 1,
+		"owner": {"id": 2, "login": "myorg", "username": "myorg"},
+		"name": "app",
+		"full_name": "myorg/app",
+		"ssh_url": "git@gitea.example.com:myorg/app.git",
+		"clone_url": "https://gitea.example.com/myorg/app.git",
+		"default_branch": "main"
+	},
+	{
+		"id": 2,
+		"owner": {"id": 2, "login": "myorg", "username": "myorg"},
+		"name": "empty",
+		"full_name": "myorg/empty",
+		"empty": true,
+		"ssh_url": "git@gitea.example.com:myorg/empty.git",
+		"clone_url": "https://gitea.example.com/myorg/empty.git",
+		"default_branch": "main"
+	}
+]`)
+		case "/api/v1/users/jdoe/repos?limit=50&page=1":
+			fmt.Fprint(w, `[
+	{
+		"id": 3,
+		"owner": {"id": 3, "login": "jdoe", "username": "jdoe"},
+		"name": "dotfiles",
+		"full_name": "jdoe/dotfiles",
+		"ssh_url": "git@gitea.example.com:jdoe/dotfiles.git",
+		"clone_url": "https://gitea.example.com/jdoe/dotfiles.git",
+		"default_branch": "master"
+	}
+]`)
+		case "/api/v1/orgs/myorg/repos?limit=50&page=2", "/api/v1/users/jdoe/repos?limit=50&page=2",
+			"/api/v1/repos/myorg/app/branches?limit=50&page=2":
+			fmt.Fprint(w, `[]`)
+		case "/api/v1/repos/myorg/app/branches/main?":
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "75f6fceff80f5aceb9b19f2ad8e6b3cfdb9ab6d4"}}`)
+		case "/api/v1/repos/jdoe/dotfiles/branches/master?":
+			fmt.Fprint(w, `{"name": "master", "commit": {"id": "2c6bd1d2ae25bb2c1b0ec2f2d9a48e8d8a8fa0cd"}}`)
+		case "/api/v1/repos/myorg/app/branches?limit=50&page=1":
+			fmt.Fprint(w, `[
+	{"name": "main", "commit": {"id": "75f6fceff80f5aceb9b19f2ad8e6b3cfdb9ab6d4"}},
+	{"name": "feature", "commit": {"id": "b8f8ae5f0c3d7d2b0c9f1e6a3d2c1b0a9f8e7d6c"}}
+]`)
+		case "/api/v1/repos/myorg/app/topics?limit=50&page=1":
+			fmt.Fprint(w, `{"topics": ["backend", "go"]}`)
+		case "/api/v1/repos/jdoe/dotfiles/topics?limit=50&page=1":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "internal error"}`)
+		case "/api/v1/repos/myorg/app/contents/README.md?ref=main":
+			fmt.Fprint(w, `{"name": "README.md", "path": "README.md", "type": "file"}`)
+		case "/api/v1/repos/myorg/app/contents/deploy?ref=main":
+			fmt.Fprint(w, `[{"name": "app.yaml", "path": "deploy/app.yaml", "type": "file"}]`)
+		case "/api/v1/repos/myorg/app/contents/notathing?ref=main":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "object does not exist"}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGiteaListRepos(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(giteaMockHandler(t)))
+	defer ts.Close()
+
+	cases := []struct {
+		name, owner, proto string
+		allBranches        bool
+		hasError           bool
+		repos              []*Repository
+	}{
+		{
+			name:  "blank protocol",
+			owner: "myorg",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@gitea.example.com:myorg/app.git", Branch: "main", SHA: "75f6fceff80f5aceb9b19f2ad8e6b3cfdb9ab6d4", Labels: []string{"backend", "go"}},
+			},
+		},
+		{
+			name:  "https protocol",
+			owner: "myorg",
+			proto: "https",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "https://gitea.example.com/myorg/app.git", Branch: "main", SHA: "75f6fceff80f5aceb9b19f2ad8e6b3cfdb9ab6d4", Labels: []string{"backend", "go"}},
+			},
+		},
+		{
+			name:     "other protocol",
+			owner:    "myorg",
+			proto:    "other",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			owner:       "myorg",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@gitea.example.com:myorg/app.git", Branch: "main", SHA: "75f6fceff80f5aceb9b19f2ad8e6b3cfdb9ab6d4", Labels: []string{"backend", "go"}},
+				{Organization: "myorg", Repository: "app", URL: "git@gitea.example.com:myorg/app.git", Branch: "feature", SHA: "b8f8ae5f0c3d7d2b0c9f1e6a3d2c1b0a9f8e7d6c", Labels: []string{"backend", "go"}},
+			},
+		},
+		{
+			name:  "user",
+			owner: "jdoe",
+			repos: []*Repository{
+				{Organization: "jdoe", Repository: "dotfiles", URL: "git@gitea.example.com:jdoe/dotfiles.git", Branch: "master", SHA: "2c6bd1d2ae25bb2c1b0ec2f2d9a48e8d8a8fa0cd", Labels: []string{}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider, err := NewGiteaProvider(context.Background(), c.owner, "access-token", ts.URL, c.allBranches)
+			assert.NoError(t, err)
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.repos, repos)
+			}
+		})
+	}
+}
+
+func TestGiteaHasPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(giteaMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewGiteaProvider(context.Background(), "myorg", "access-token", ts.URL, false)
+	assert.NoError(t, err)
+	repo := &Repository{
+		Organization: "myorg",
+		Repository:   "app",
+		Branch:       "main",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "README.md")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "deploy")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestGiteaUsesContextOfCall(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(giteaMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewGiteaProvider(context.Background(), "myorg", "access-token", ts.URL, false)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = provider.ListRepos(ctx, "", true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), context.Canceled.Error())
+	_, err = provider.RepoHasPath(ctx, &Repository{Organization: "myorg", Repository: "app", Branch: "main"}, "README.md")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewGiteaProviderRequiresURL(t *testing.T) {
+	_, err := NewGiteaProvider(context.Background(), "myorg", "", "", false)
+	assert.Error(t, err)
+}
@@ -3,8 +3,10 @@ package scm_provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
 	"github.com/google/go-github/v35/github"
 	"golang.org/x/oauth2"
 )
@@ -28,6 +30,8 @@ func NewGithubProvider(ctx context.Context, organization string, token string, u
 			&oauth2.Token{AccessToken: token},
 		)
 	}
+	// The rate limit transport goes under the oauth2 one, so that it sees the credentials of the requests.
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: ratelimit.NewTransport(nil)})
 	httpClient := oauth2.NewClient(ctx, ts)
 	var client *github.Client
 	if url == "" {
@@ -42,15 +46,16 @@ func NewGithubProvider(ctx context.Context, organization string, token string, u
 	return &GithubProvider{client: client, organization: organization, allBranches: allBranches}, nil
 }
 
-func (g *GithubProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+func (g *GithubProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
 	opt := &github.RepositoryListByOrgOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
 	for {
 		githubRepos, resp, err := g.client.Repositories.ListByOrg(ctx, g.organization, opt)
 		if err != nil {
-			return nil, fmt.Errorf("error listing repositories for %s: %v", g.organization, err)
+			return nil, fmt.Errorf("error listing repositories for %s: %w", g.organization, ratelimit.FromGithub(err))
 		}
 		for _, githubRepo := range githubRepos {
 			var url string
@@ -66,7 +71,10 @@ func (g *GithubProvider) ListRepos(ctx context.Context, cloneProtocol string) ([
 
 			branches, err := g.listBranches(ctx, githubRepo)
 			if err != nil {
-				return nil, fmt.Errorf("error listing branches for %s/%s: %v", githubRepo.Owner.GetLogin(), githubRepo.GetName(), err)
+				if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %w", githubRepo.Owner.GetLogin(), githubRepo.GetName(), ratelimit.FromGithub(err))); err != nil {
+					return nil, err
+				}
+				continue
 			}
 
 			for _, branch := range branches {
@@ -85,7 +93,7 @@ func (g *GithubProvider) ListRepos(ctx context.Context, cloneProtocol string) ([
 		}
 		opt.Page = resp.NextPage
 	}
-	return repos, nil
+	return repos, repoErrs.err()
 }
 
 func (g *GithubProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
@@ -97,7 +105,7 @@ func (g *GithubProvider) RepoHasPath(ctx context.Context, repo *Repository, path
 		return false, nil
 	}
 	if err != nil {
-		return false, err
+		return false, ratelimit.FromGithub(err)
 	}
 	return true, nil
 }
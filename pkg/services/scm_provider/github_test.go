@@ -70,7 +70,7 @@ func TestGithubListRepos(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			provider, _ := NewGithubProvider(context.Background(), "argoproj", "", "", c.allBranches)
-			rawRepos, err := provider.ListRepos(context.Background(), c.proto)
+			rawRepos, err := provider.ListRepos(context.Background(), c.proto, true)
 			if c.hasError {
 				assert.NotNil(t, err)
 			} else {
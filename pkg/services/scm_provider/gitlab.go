@@ -3,8 +3,10 @@ package scm_provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
 	gitlab "github.com/xanzy/go-gitlab"
 )
 
@@ -22,16 +24,17 @@ func NewGitlabProvider(ctx context.Context, organization string, token string, u
 	if token == "" {
 		token = os.Getenv("GITLAB_TOKEN")
 	}
+	httpClient := &http.Client{Transport: ratelimit.NewTransport(nil)}
 	var client *gitlab.Client
 	if url == "" {
 		var err error
-		client, err = gitlab.NewClient(token)
+		client, err = gitlab.NewClient(token, gitlab.WithHTTPClient(httpClient))
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		var err error
-		client, err = gitlab.NewClient(token, gitlab.WithBaseURL(url))
+		client, err = gitlab.NewClient(token, gitlab.WithHTTPClient(httpClient), gitlab.WithBaseURL(url))
 		if err != nil {
 			return nil, err
 		}
@@ -39,16 +42,17 @@ func NewGitlabProvider(ctx context.Context, organization string, token string, u
 	return &GitlabProvider{client: client, organization: organization, allBranches: allBranches, includeSubgroups: includeSubgroups}, nil
 }
 
-func (g *GitlabProvider) ListRepos(ctx context.Context, cloneProtocol string) ([]*Repository, error) {
+func (g *GitlabProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
 	opt := &gitlab.ListGroupProjectsOptions{
 		ListOptions:      gitlab.ListOptions{PerPage: 100},
 		IncludeSubgroups: &g.includeSubgroups,
 	}
 	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
 	for {
 		gitlabRepos, resp, err := g.client.Groups.ListGroupProjects(g.organization, opt)
 		if err != nil {
-			return nil, fmt.Errorf("error listing projects for %s: %v", g.organization, err)
+			return nil, fmt.Errorf("error listing projects for %s: %w", g.organization, err)
 		}
 		for _, gitlabRepo := range gitlabRepos {
 			var url string
@@ -64,7 +68,10 @@ func (g *GitlabProvider) ListRepos(ctx context.Context, cloneProtocol string) ([
 
 			branches, err := g.listBranches(ctx, gitlabRepo)
 			if err != nil {
-				return nil, fmt.Errorf("error listing branches for %s/%s: %v", g.organization, gitlabRepo.Name, err)
+				if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %w", g.organization, gitlabRepo.Name, err)); err != nil {
+					return nil, err
+				}
+				continue
 			}
 
 			for _, branch := range branches {
@@ -83,7 +90,7 @@ func (g *GitlabProvider) ListRepos(ctx context.Context, cloneProtocol string) ([
 		}
 		opt.Page = resp.NextPage
 	}
-	return repos, nil
+	return repos, repoErrs.err()
 }
 
 func (g *GitlabProvider) RepoHasPath(_ context.Context, repo *Repository, path string) (bool, error) {
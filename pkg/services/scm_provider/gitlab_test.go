@@ -44,7 +44,7 @@ func TestGitlabListRepos(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			provider, _ := NewGitlabProvider(context.Background(), "test-argocd-proton", "", "", c.allBranches, c.includeSubgroups)
-			rawRepos, err := provider.ListRepos(context.Background(), c.proto)
+			rawRepos, err := provider.ListRepos(context.Background(), c.proto, true)
 			if c.hasError {
 				assert.NotNil(t, err)
 			} else {
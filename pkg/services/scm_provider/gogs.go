@@ -0,0 +1,188 @@
+package scm_provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type GogsProvider struct {
+	client      *http.Client
+	url         string
+	owner       string
+	token       string
+	allBranches bool
+}
+
+var _ SCMProviderService = &GogsProvider{}
+
+// gogsRepo is the subset of the Gogs Repository entity used by the provider.
+type gogsRepo struct {
+	Name  string `json:"name"`
+	Owner struct {
+		UserName string `json:"username"`
+	} `json:"owner"`
+	Empty         bool   `json:"empty"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// gogsBranch is the subset of the Gogs Branch entity used by the provider.
+type gogsBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		ID string `json:"id"`
+	} `json:"commit"`
+}
+
+// NewGogsProvider returns a provider listing the repositories of a Gogs organization. If token is empty, requests are
+// made anonymously.
+func NewGogsProvider(ctx context.Context, owner, token, url string, allBranches bool) (*GogsProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("gogs API URL is required")
+	}
+	if owner == "" {
+		return nil, fmt.Errorf("gogs organization is required")
+	}
+	return &GogsProvider{
+		client:      &http.Client{},
+		url:         strings.TrimSuffix(url, "/"),
+		owner:       owner,
+		token:       token,
+		allBranches: allBranches,
+	}, nil
+}
+
+func (g *GogsProvider) ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
+	// Gogs returns all the repositories of an organization at once.
+	var gogsRepos []gogsRepo
+	if err := g.get(ctx, fmt.Sprintf("/orgs/%s/repos", url.PathEscape(g.owner)), nil, &gogsRepos); err != nil {
+		return nil, fmt.Errorf("error listing repositories for %s: %v", g.owner, err)
+	}
+	repos := []*Repository{}
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
+	for _, gogsRepo := range gogsRepos {
+		// Empty repositories have no branches, and so nothing to generate.
+		if gogsRepo.Empty {
+			continue
+		}
+
+		var url string
+		switch cloneProtocol {
+		// Default to SSH if unspecified (i.e. if "").
+		case "", "ssh":
+			url = gogsRepo.SSHURL
+		case "https":
+			url = gogsRepo.CloneURL
+		default:
+			return nil, fmt.Errorf("unknown clone protocol for Gogs %v", cloneProtocol)
+		}
+
+		branches, err := g.listBranches(ctx, gogsRepo)
+		if err != nil {
+			if err := repoErrs.add(fmt.Errorf("error listing branches for %s/%s: %v", gogsRepo.Owner.UserName, gogsRepo.Name, err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, branch := range branches {
+			repos = append(repos, &Repository{
+				Organization: gogsRepo.Owner.UserName,
+				Repository:   gogsRepo.Name,
+				URL:          url,
+				Branch:       branch.Name,
+				SHA:          branch.Commit.ID,
+			})
+		}
+	}
+	return repos, repoErrs.err()
+}
+
+func (g *GogsProvider) RepoHasPath(ctx context.Context, repo *Repository, path string) (bool, error) {
+	query := url.Values{}
+	query.Set("ref", repo.Branch)
+	err := g.get(ctx, fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(repo.Organization), url.PathEscape(repo.Repository), escapePath(path)), query, nil)
+	// 404s are not an error here, just a normal false.
+	if isGogsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (g *GogsProvider) listBranches(ctx context.Context, repo gogsRepo) ([]gogsBranch, error) {
+	repoPath := fmt.Sprintf("/repos/%s/%s/branches", url.PathEscape(repo.Owner.UserName), url.PathEscape(repo.Name))
+	// If we don't specifically want to query for all branches, just use the default branch and call it a day.
+	if !g.allBranches {
+		var branch gogsBranch
+		if err := g.get(ctx, repoPath+"/"+url.PathEscape(repo.DefaultBranch), nil, &branch); err != nil {
+			return nil, err
+		}
+		return []gogsBranch{branch}, nil
+	}
+	// Otherwise, list all the branches, which Gogs returns at once.
+	branches := []gogsBranch{}
+	if err := g.get(ctx, repoPath, nil, &branches); err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// gogsError is returned for a response of the Gogs API with an unexpected status.
+type gogsError struct {
+	statusCode int
+	message    string
+}
+
+func (e *gogsError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.statusCode, e.message)
+}
+
+func isGogsNotFound(err error) bool {
+	gogsErr, ok := err.(*gogsError)
+	return ok && gogsErr.statusCode == http.StatusNotFound
+}
+
+// get makes a GET request to path of the API, and decodes the response into out, unless it is nil.
+func (g *GogsProvider) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	reqURL := g.url + "/api/v1" + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &gogsError{statusCode: resp.StatusCode, message: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package scm_provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gogsMockHandler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token gogs-token" {
+			t.Errorf("unexpected authorization for %s", r.RequestURI)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.RequestURI {
+		case "/api/v1/orgs/myorg/repos":
+			fmt.Fprint(w, `[
+	{"name": "app", "owner": {"username": "myorg"}, "empty": false, "clone_url": "https://gogs.example.com/myorg/app.git", "ssh_url": "git@gogs.example.com:myorg/app.git", "default_branch": "master"},
+	{"name": "broken", "owner": {"username": "myorg"}, "empty": false, "clone_url": "https://gogs.example.com/myorg/broken.git", "ssh_url": "git@gogs.example.com:myorg/broken.git", "default_branch": "master"},
+	{"name": "empty", "owner": {"username": "myorg"}, "empty": true, "clone_url": "https://gogs.example.com/myorg/empty.git", "ssh_url": "git@gogs.example.com:myorg/empty.git", "default_branch": "master"}
+]`)
+		case "/api/v1/repos/myorg/app/branches/master":
+			fmt.Fprint(w, `{"name": "master", "commit": {"id": "5b3f8c1d2e4a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"}}`)
+		case "/api/v1/repos/myorg/app/branches":
+			fmt.Fprint(w, `[
+	{"name": "master", "commit": {"id": "5b3f8c1d2e4a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"}},
+	{"name": "feature", "commit": {"id": "c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0"}}
+]`)
+		case "/api/v1/repos/myorg/broken/branches/master", "/api/v1/repos/myorg/broken/branches":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "internal error")
+		case "/api/v1/repos/myorg/app/contents/deploy?ref=master":
+			fmt.Fprint(w, `[{"type": "file", "name": "app.yaml", "path": "deploy/app.yaml"}]`)
+		case "/api/v1/repos/myorg/app/contents/docs/release%20%231?ref=master":
+			fmt.Fprint(w, `[{"type": "file", "name": "notes.md", "path": "docs/release #1/notes.md"}]`)
+		case "/api/v1/repos/myorg/app/contents/notathing?ref=master":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "object does not exist"}`)
+		default:
+			t.Errorf("unexpected request: %s", r.RequestURI)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestGogsListRepos(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gogsMockHandler(t)))
+	defer ts.Close()
+
+	cases := []struct {
+		name, proto string
+		allBranches bool
+		hasError    bool
+		repos       []*Repository
+	}{
+		{
+			name: "blank protocol",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@gogs.example.com:myorg/app.git", Branch: "master", SHA: "5b3f8c1d2e4a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"},
+			},
+		},
+		{
+			name:  "https protocol",
+			proto: "https",
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "https://gogs.example.com/myorg/app.git", Branch: "master", SHA: "5b3f8c1d2e4a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"},
+			},
+		},
+		{
+			name:     "other protocol",
+			proto:    "other",
+			hasError: true,
+		},
+		{
+			name:        "all branches",
+			allBranches: true,
+			repos: []*Repository{
+				{Organization: "myorg", Repository: "app", URL: "git@gogs.example.com:myorg/app.git", Branch: "master", SHA: "5b3f8c1d2e4a6b7c8d9e0f1a2b3c4d5e6f7a8b9c"},
+				{Organization: "myorg", Repository: "app", URL: "git@gogs.example.com:myorg/app.git", Branch: "feature", SHA: "c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider, err := NewGogsProvider(context.Background(), "myorg", "gogs-token", ts.URL, c.allBranches)
+			assert.NoError(t, err)
+			repos, err := provider.ListRepos(context.Background(), c.proto, true)
+			if c.hasError {
+				assert.Error(t, err)
+				return
+			}
+			// The repository whose branches can't be listed is skipped, and its error returned.
+			var repoErrs *RepositoryErrors
+			if assert.ErrorAs(t, err, &repoErrs) {
+				assert.EqualError(t, repoErrs, "error listing branches for myorg/broken: unexpected status 500: internal error")
+			}
+			assert.Equal(t, c.repos, repos)
+		})
+	}
+}
+
+func TestGogsListReposStopsAtFirstError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gogsMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewGogsProvider(context.Background(), "myorg", "gogs-token", ts.URL, false)
+	assert.NoError(t, err)
+	repos, err := provider.ListRepos(context.Background(), "", false)
+	var repoErrs *RepositoryErrors
+	assert.False(t, errors.As(err, &repoErrs))
+	assert.EqualError(t, err, "error listing branches for myorg/broken: unexpected status 500: internal error")
+	assert.Nil(t, repos)
+}
+
+func TestGogsListReposError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message": "invalid token"}`)
+	}))
+	defer ts.Close()
+
+	provider, err := NewGogsProvider(context.Background(), "myorg", "wrong", ts.URL, false)
+	assert.NoError(t, err)
+	_, err = provider.ListRepos(context.Background(), "", true)
+	assert.EqualError(t, err, "error listing repositories for myorg: unexpected status 401: {\"message\": \"invalid token\"}")
+}
+
+func TestGogsHasPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(gogsMockHandler(t)))
+	defer ts.Close()
+
+	provider, err := NewGogsProvider(context.Background(), "myorg", "gogs-token", ts.URL, false)
+	assert.NoError(t, err)
+	repo := &Repository{
+		Organization: "myorg",
+		Repository:   "app",
+		Branch:       "master",
+	}
+
+	ok, err := provider.RepoHasPath(context.Background(), repo, "deploy")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "/docs/release #1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = provider.RepoHasPath(context.Background(), repo, "notathing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewGogsProviderValidation(t *testing.T) {
+	_, err := NewGogsProvider(context.Background(), "myorg", "", "", false)
+	assert.Error(t, err)
+	_, err = NewGogsProvider(context.Background(), "", "", "https://gogs.example.com", false)
+	assert.EqualError(t, err, "gogs organization is required")
+}
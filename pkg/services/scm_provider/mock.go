@@ -4,14 +4,19 @@ import "context"
 
 type MockProvider struct {
 	Repos []*Repository
+	// PathErrors are returned by RepoHasPath for the repositories with the names they are keyed by.
+	PathErrors map[string]error
 }
 
 var _ SCMProviderService = &MockProvider{}
 
-func (m *MockProvider) ListRepos(_ context.Context, _ string) ([]*Repository, error) {
+func (m *MockProvider) ListRepos(_ context.Context, _ string, _ bool) ([]*Repository, error) {
 	return m.Repos, nil
 }
 
-func (*MockProvider) RepoHasPath(_ context.Context, repo *Repository, path string) (bool, error) {
+func (m *MockProvider) RepoHasPath(_ context.Context, repo *Repository, path string) (bool, error) {
+	if err, ok := m.PathErrors[repo.Repository]; ok {
+		return false, err
+	}
 	return path == repo.Repository, nil
 }
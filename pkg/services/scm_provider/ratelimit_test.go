@@ -0,0 +1,63 @@
+package scm_provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubListReposRateLimited(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// The quota runs out listing the repositories, before their branches can be listed.
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		_, _ = w.Write([]byte(`[{"name": "myrepo", "owner": {"login": "myorg"}, "clone_url": "https://github.example.com/myorg/myrepo.git", "default_branch": "main"}]`))
+	}))
+	defer ts.Close()
+
+	provider, err := NewGithubProvider(context.Background(), "myorg", "token", ts.URL, false)
+	assert.NoError(t, err)
+	// Rather than skipping every repository, listing fails so that the ApplicationSet is requeued.
+	_, err = provider.ListRepos(context.Background(), "https", true)
+	var rateLimitErr *ratelimit.Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.Equal(t, 1, requests)
+}
+
+func TestGitlabListReposRateLimited(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	provider, err := NewGitlabProvider(context.Background(), "mygroup", "token", ts.URL, false, false)
+	assert.NoError(t, err)
+	_, err = provider.ListRepos(context.Background(), "https", true)
+	var rateLimitErr *ratelimit.Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+	assert.InDelta(t, time.Hour.Seconds(), rateLimitErr.RetryAfter.Seconds(), 5)
+}
+
+func TestBitbucketServerListReposRateLimited(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	provider, err := NewBitbucketServerProvider(context.Background(), "PROJECT", ts.URL, "", "", "token", false)
+	assert.NoError(t, err)
+	_, err = provider.ListRepos(context.Background(), "https", true)
+	var rateLimitErr *ratelimit.Error
+	assert.True(t, errors.As(err, &rateLimitErr))
+}
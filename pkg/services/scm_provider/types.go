@@ -2,7 +2,11 @@ package scm_provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"regexp"
+
+	"github.com/argoproj/applicationset/pkg/services/ratelimit"
 )
 
 // An abstract repository from an API provider.
@@ -16,7 +20,10 @@ type Repository struct {
 }
 
 type SCMProviderService interface {
-	ListRepos(context.Context, string) ([]*Repository, error)
+	// ListRepos lists the repositories with the given clone protocol. If continueOnError is true, the errors reading
+	// individual repositories are returned as RepositoryErrors along with the repositories that could be read;
+	// otherwise listing stops at the first error.
+	ListRepos(ctx context.Context, cloneProtocol string, continueOnError bool) ([]*Repository, error)
 	RepoHasPath(context.Context, *Repository, string) (bool, error)
 }
 
@@ -27,3 +34,47 @@ type Filter struct {
 	LabelMatch      *regexp.Regexp
 	BranchMatch     *regexp.Regexp
 }
+
+// RepositoryErrors holds the errors reading individual repositories, eg listing their branches. It is returned along
+// with the repositories that could be read.
+type RepositoryErrors struct {
+	Errors []error
+}
+
+func (e *RepositoryErrors) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	// Only the first error is included, to keep the size of the ApplicationSet status reasonable.
+	return fmt.Sprintf("%v (and %d more)", e.Errors[0], len(e.Errors)-1)
+}
+
+// repositoryErrors returns a RepositoryErrors holding errs, or nil if there are none.
+func repositoryErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &RepositoryErrors{Errors: errs}
+}
+
+// repositoryErrorCollector collects the errors reading individual repositories if continueOnError is set. Otherwise
+// add returns the first error, so that listing stops right away rather than after reading every other repository.
+// An exhausted rate limit is always returned, since reading the other repositories would fail too.
+type repositoryErrorCollector struct {
+	continueOnError bool
+	errs            []error
+}
+
+func (c *repositoryErrorCollector) add(err error) error {
+	var rateLimitErr *ratelimit.Error
+	if !c.continueOnError || errors.As(err, &rateLimitErr) {
+		return err
+	}
+	c.errs = append(c.errs, err)
+	return nil
+}
+
+// err returns a RepositoryErrors holding the collected errors, or nil if there are none.
+func (c *repositoryErrorCollector) err() error {
+	return repositoryErrors(c.errs)
+}
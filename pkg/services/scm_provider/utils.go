@@ -2,8 +2,11 @@ package scm_provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
 )
@@ -76,20 +79,30 @@ func matchFilter(ctx context.Context, provider SCMProviderService, repo *Reposit
 	return true, nil
 }
 
-func ListRepos(ctx context.Context, provider SCMProviderService, filters []argoprojiov1alpha1.SCMProviderGeneratorFilter, cloneProtocol string) ([]*Repository, error) {
+// ListRepos lists the repositories of the provider matching any of the filters. If continueOnError is true, the
+// errors reading or filtering individual repositories are returned as RepositoryErrors along with the repositories
+// that could be; otherwise the first error is returned right away.
+func ListRepos(ctx context.Context, provider SCMProviderService, filters []argoprojiov1alpha1.SCMProviderGeneratorFilter, cloneProtocol string, continueOnError bool) ([]*Repository, error) {
 	compiledFilters, err := compileFilters(filters)
 	if err != nil {
 		return nil, err
 	}
 
-	repos, err := provider.ListRepos(ctx, cloneProtocol)
+	// The repositories that could be read are filtered even if others couldn't, so that callers can continue with
+	// them.
+	repos, err := provider.ListRepos(ctx, cloneProtocol, continueOnError)
+	repoErrs := &repositoryErrorCollector{continueOnError: continueOnError}
 	if err != nil {
-		return nil, err
+		var listErrs *RepositoryErrors
+		if !errors.As(err, &listErrs) {
+			return nil, err
+		}
+		repoErrs.errs = append(repoErrs.errs, listErrs.Errors...)
 	}
 
 	// Special case, if we have no filters, allow everything.
 	if len(compiledFilters) == 0 {
-		return repos, nil
+		return repos, repoErrs.err()
 	}
 
 	filteredRepos := make([]*Repository, 0, len(repos))
@@ -97,7 +110,10 @@ func ListRepos(ctx context.Context, provider SCMProviderService, filters []argop
 		for _, filter := range compiledFilters {
 			matches, err := matchFilter(ctx, provider, repo, filter)
 			if err != nil {
-				return nil, err
+				if err := repoErrs.add(fmt.Errorf("error filtering %s/%s@%s: %w", repo.Organization, repo.Repository, repo.Branch, err)); err != nil {
+					return nil, err
+				}
+				break
 			}
 			if matches {
 				filteredRepos = append(filteredRepos, repo)
@@ -105,5 +121,14 @@ func ListRepos(ctx context.Context, provider SCMProviderService, filters []argop
 			}
 		}
 	}
-	return filteredRepos, nil
+	return filteredRepos, repoErrs.err()
+}
+
+// escapePath trims the slashes around a path of a repository, and escapes each of its segments for use in a URL.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
 }
@@ -2,6 +2,7 @@ package scm_provider
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
@@ -34,7 +35,7 @@ func TestFilterRepoMatch(t *testing.T) {
 			RepositoryMatch: strp("n|hr"),
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 2)
 	assert.Equal(t, "one", repos[0].Repository)
@@ -63,7 +64,7 @@ func TestFilterLabelMatch(t *testing.T) {
 			LabelMatch: strp("^prod-.*$"),
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 2)
 	assert.Equal(t, "one", repos[0].Repository)
@@ -89,7 +90,7 @@ func TestFilterPatchExists(t *testing.T) {
 			PathsExist: []string{"two"},
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 1)
 	assert.Equal(t, "two", repos[0].Repository)
@@ -108,7 +109,7 @@ func TestFilterRepoMatchBadRegexp(t *testing.T) {
 			RepositoryMatch: strp("("),
 		},
 	}
-	_, err := ListRepos(context.Background(), provider, filters, "")
+	_, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.NotNil(t, err)
 }
 
@@ -125,7 +126,7 @@ func TestFilterLabelMatchBadRegexp(t *testing.T) {
 			LabelMatch: strp("("),
 		},
 	}
-	_, err := ListRepos(context.Background(), provider, filters, "")
+	_, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.NotNil(t, err)
 }
 
@@ -159,7 +160,7 @@ func TestFilterBranchMatch(t *testing.T) {
 			BranchMatch: strp("w"),
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 2)
 	assert.Equal(t, "one", repos[0].Repository)
@@ -191,7 +192,7 @@ func TestMultiFilterAnd(t *testing.T) {
 			LabelMatch:      strp("^prod-.*$"),
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 1)
 	assert.Equal(t, "two", repos[0].Repository)
@@ -222,7 +223,7 @@ func TestMultiFilterOr(t *testing.T) {
 			LabelMatch: strp("^prod-.*$"),
 		},
 	}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 3)
 	assert.Equal(t, "one", repos[0].Repository)
@@ -248,10 +249,77 @@ func TestNoFilters(t *testing.T) {
 		},
 	}
 	filters := []argoprojiov1alpha1.SCMProviderGeneratorFilter{}
-	repos, err := ListRepos(context.Background(), provider, filters, "")
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
 	assert.Nil(t, err)
 	assert.Len(t, repos, 3)
 	assert.Equal(t, "one", repos[0].Repository)
 	assert.Equal(t, "two", repos[1].Repository)
 	assert.Equal(t, "three", repos[2].Repository)
 }
+
+func TestFilterPathErrors(t *testing.T) {
+	provider := &MockProvider{
+		Repos: []*Repository{
+			{
+				Organization: "org",
+				Repository:   "one",
+				Branch:       "main",
+			},
+			{
+				Organization: "org",
+				Repository:   "two",
+				Branch:       "main",
+			},
+		},
+		PathErrors: map[string]error{
+			"one": errors.New("not available"),
+		},
+	}
+	filters := []argoprojiov1alpha1.SCMProviderGeneratorFilter{
+		{
+			PathsExist: []string{"two"},
+		},
+	}
+	repos, err := ListRepos(context.Background(), provider, filters, "", true)
+	var repoErrs *RepositoryErrors
+	assert.True(t, errors.As(err, &repoErrs))
+	assert.EqualError(t, err, "error filtering org/one@main: not available")
+	assert.Len(t, repos, 1)
+	assert.Equal(t, "two", repos[0].Repository)
+}
+
+func TestFilterPathErrorsStopAtFirstError(t *testing.T) {
+	provider := &MockProvider{
+		Repos: []*Repository{
+			{
+				Organization: "org",
+				Repository:   "one",
+				Branch:       "main",
+			},
+			{
+				Organization: "org",
+				Repository:   "two",
+				Branch:       "main",
+			},
+		},
+		PathErrors: map[string]error{
+			"one": errors.New("not available"),
+		},
+	}
+	filters := []argoprojiov1alpha1.SCMProviderGeneratorFilter{
+		{
+			PathsExist: []string{"two"},
+		},
+	}
+	repos, err := ListRepos(context.Background(), provider, filters, "", false)
+	var repoErrs *RepositoryErrors
+	assert.False(t, errors.As(err, &repoErrs))
+	assert.EqualError(t, err, "error filtering org/one@main: not available")
+	assert.Nil(t, repos)
+}
+
+func TestRepositoryErrors(t *testing.T) {
+	err := &RepositoryErrors{Errors: []error{errors.New("one"), errors.New("two"), errors.New("three")}}
+	assert.EqualError(t, err, "one (and 2 more)")
+	assert.Nil(t, repositoryErrors(nil))
+}
@@ -0,0 +1,11 @@
+package utils
+
+// ContainsString returns true if values contains value.
+func ContainsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
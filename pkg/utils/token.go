@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/credentials"
+)
+
+// TokenEnvPrefix is the prefix of the names of the controller environment variables tokens may be read from.
+// Restricting the names keeps ApplicationSets from reading unrelated environment variables of the controller.
+const TokenEnvPrefix = "ARGOCD_APPSET_TOKEN_"
+
+// TokenReader reads the tokens referenced by tokenFrom from the environment, file system or secret store of the
+// controller.
+type TokenReader struct {
+	// Dir is the directory token files are resolved relative to, outside of which they can't be read. Reading
+	// tokens from files is disabled if empty.
+	Dir string
+	// Vault reads tokens from HashiCorp Vault. Reading tokens from Vault is disabled if nil.
+	Vault credentials.SecretReader
+	// VaultPathPrefix is the path in Vault token secrets must lie under, so that ApplicationSets can't read other
+	// secrets the controller has access to. No secret can be read from Vault if empty.
+	VaultPathPrefix string
+	// OIDCExchange exchanges the identity token of the controller for tokens. Token exchange is disabled if nil.
+	OIDCExchange credentials.TokenExchanger
+	// OIDCExchangeScopes are the patterns, as matched by path.Match, of the scopes tokens may be requested for, so
+	// that ApplicationSets can't request tokens for any scope the controller's identity is trusted with.
+	OIDCExchangeScopes []string
+	// OIDCExchangeIdentities are the identities tokens may be requested under.
+	OIDCExchangeIdentities []string
+}
+
+// Read reads the token referenced by source. Surrounding whitespace, such as a trailing newline, is removed from
+// the token.
+func (r *TokenReader) Read(ctx context.Context, source *argoprojiov1alpha1.TokenSource) (string, error) {
+	set := 0
+	for _, isSet := range []bool{source.File != "", source.Env != "", source.Vault != nil, source.OIDCExchange != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", fmt.Errorf("exactly one of file, env, vault and oidcExchange must be set in tokenFrom")
+	}
+
+	switch {
+	case source.Env != "":
+		return r.readEnv(source.Env)
+	case source.Vault != nil:
+		return r.readVault(ctx, source.Vault)
+	case source.OIDCExchange != nil:
+		return r.exchange(ctx, source.OIDCExchange)
+	default:
+		return r.readFile(source.File)
+	}
+}
+
+func (r *TokenReader) readEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, TokenEnvPrefix) {
+		return "", fmt.Errorf("environment variable %q can't be read as a token: its name must start with %s", name, TokenEnvPrefix)
+	}
+	token, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+	return strings.TrimSpace(token), nil
+}
+
+func (r *TokenReader) readFile(file string) (string, error) {
+	if r.Dir == "" {
+		return "", fmt.Errorf("reading tokens from files is disabled: the controller has no token directory")
+	}
+	if filepath.IsAbs(file) {
+		return "", fmt.Errorf("token file %q must be relative to the token directory", file)
+	}
+	path := filepath.Join(r.Dir, file)
+	if !isWithinDir(r.Dir, path) {
+		return "", fmt.Errorf("token file %q is outside of the token directory", file)
+	}
+	// Symlinks are resolved, so that a link in the token directory can't point outside of it. The token directory
+	// itself may be a link, eg to the mount of a CSI secret driver.
+	dir, err := filepath.EvalSymlinks(r.Dir)
+	if err != nil {
+		return "", fmt.Errorf("error resolving token directory: %v", err)
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file %q: %v", file, err)
+	}
+	if !isWithinDir(dir, path) {
+		return "", fmt.Errorf("token file %q is outside of the token directory", file)
+	}
+	token, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file %q: %v", file, err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// isWithinDir returns whether path is dir or lies under it, judging by their names only.
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (r *TokenReader) readVault(ctx context.Context, ref *argoprojiov1alpha1.VaultSecretRef) (string, error) {
+	if r.Vault == nil {
+		return "", fmt.Errorf("reading tokens from vault is disabled: the controller has no vault address")
+	}
+	path, ok := vaultPathWithin(r.VaultPathPrefix, ref.Path)
+	if !ok {
+		return "", fmt.Errorf("vault secret %q can't be read as a token: its path must be under the vault path prefix of the controller", ref.Path)
+	}
+	token, err := r.Vault.ReadSecret(ctx, path, ref.Key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// vaultPathWithin cleans path, and returns it along with whether it lies under prefix. The cleaned path is returned
+// so that the path read is the one checked, and not one which Vault might resolve differently.
+func vaultPathWithin(prefix, path string) (string, bool) {
+	prefix = strings.Trim(pathpkg.Clean("/"+prefix), "/")
+	path = strings.Trim(pathpkg.Clean("/"+path), "/")
+	if prefix == "" {
+		return path, false
+	}
+	return path, path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func (r *TokenReader) exchange(ctx context.Context, exchange *argoprojiov1alpha1.OIDCTokenExchange) (string, error) {
+	if r.OIDCExchange == nil {
+		return "", fmt.Errorf("token exchange is disabled: the controller has no token exchange URL")
+	}
+	if !matchesAny(r.OIDCExchangeScopes, exchange.Scope) {
+		return "", fmt.Errorf("token exchange scope %q is not allowed by the controller", exchange.Scope)
+	}
+	if !ContainsString(r.OIDCExchangeIdentities, exchange.Identity) {
+		return "", fmt.Errorf("token exchange identity %q is not allowed by the controller", exchange.Identity)
+	}
+	return r.OIDCExchange.Exchange(ctx, exchange.Scope, exchange.Identity)
+}
+
+// matchesAny returns whether value matches any of the patterns, as matched by path.Match.
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, err := pathpkg.Match(pattern, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
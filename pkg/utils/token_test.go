@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	argoprojiov1alpha1 "github.com/argoproj/applicationset/api/v1alpha1"
+	"github.com/argoproj/applicationset/pkg/services/credentials"
+)
+
+// fakeSecretReader maps secret paths to their keys and values.
+type fakeSecretReader map[string]map[string]string
+
+func (f fakeSecretReader) ReadSecret(_ context.Context, path, key string) (string, error) {
+	value, ok := f[path][key]
+	if !ok {
+		return "", fmt.Errorf("key %q in vault secret %s not found", key, path)
+	}
+	return value, nil
+}
+
+type fakeTokenExchanger struct{}
+
+func (fakeTokenExchanger) Exchange(_ context.Context, scope, identity string) (string, error) {
+	return scope + " as " + identity, nil
+}
+
+func TestTokenReaderRead(t *testing.T) {
+	tokenDir, err := ioutil.TempDir("", "tokens")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tokenDir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(tokenDir, "github"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tokenDir, "github", "token"), []byte("file-token\n"), 0600))
+	outsideDir, err := ioutil.TempDir("", "outside")
+	assert.NoError(t, err)
+	defer os.RemoveAll(outsideDir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(outsideDir, "secret"), []byte("outside-secret"), 0600))
+	// Links within the token directory, like those of Secret volumes, are followed, but not those leading out of it.
+	assert.NoError(t, os.Symlink(filepath.Join("github", "token"), filepath.Join(tokenDir, "linked")))
+	assert.NoError(t, os.Symlink(filepath.Join(outsideDir, "secret"), filepath.Join(tokenDir, "escape")))
+
+	os.Setenv("ARGOCD_APPSET_TOKEN_GITHUB", "env-token")
+	defer os.Unsetenv("ARGOCD_APPSET_TOKEN_GITHUB")
+	os.Setenv("APPSET_TEST_UNPREFIXED", "env-token")
+	defer os.Unsetenv("APPSET_TEST_UNPREFIXED")
+
+	cases := []struct {
+		name     string
+		source   argoprojiov1alpha1.TokenSource
+		tokenDir string
+		vault    credentials.SecretReader
+		exchange credentials.TokenExchanger
+		token    string
+		hasError bool
+	}{
+		{
+			name:     "file",
+			source:   argoprojiov1alpha1.TokenSource{File: "github/token"},
+			tokenDir: tokenDir,
+			token:    "file-token",
+		},
+		{
+			name:     "file without token directory",
+			source:   argoprojiov1alpha1.TokenSource{File: "github/token"},
+			hasError: true,
+		},
+		{
+			name:     "absolute file",
+			source:   argoprojiov1alpha1.TokenSource{File: filepath.Join(tokenDir, "github", "token")},
+			tokenDir: tokenDir,
+			hasError: true,
+		},
+		{
+			name:     "file outside of token directory",
+			source:   argoprojiov1alpha1.TokenSource{File: "../etc/passwd"},
+			tokenDir: tokenDir,
+			hasError: true,
+		},
+		{
+			name:     "link within token directory",
+			source:   argoprojiov1alpha1.TokenSource{File: "linked"},
+			tokenDir: tokenDir,
+			token:    "file-token",
+		},
+		{
+			name:     "link out of token directory",
+			source:   argoprojiov1alpha1.TokenSource{File: "escape"},
+			tokenDir: tokenDir,
+			hasError: true,
+		},
+		{
+			name:     "missing file",
+			source:   argoprojiov1alpha1.TokenSource{File: "gitlab/token"},
+			tokenDir: tokenDir,
+			hasError: true,
+		},
+		{
+			name:   "env",
+			source: argoprojiov1alpha1.TokenSource{Env: "ARGOCD_APPSET_TOKEN_GITHUB"},
+			token:  "env-token",
+		},
+		{
+			name:     "env without prefix",
+			source:   argoprojiov1alpha1.TokenSource{Env: "APPSET_TEST_UNPREFIXED"},
+			hasError: true,
+		},
+		{
+			name:     "unset env",
+			source:   argoprojiov1alpha1.TokenSource{Env: "ARGOCD_APPSET_TOKEN_GITLAB"},
+			hasError: true,
+		},
+		{
+			name:     "both file and env",
+			source:   argoprojiov1alpha1.TokenSource{File: "github/token", Env: "ARGOCD_APPSET_TOKEN_GITHUB"},
+			tokenDir: tokenDir,
+			hasError: true,
+		},
+		{
+			name:   "vault",
+			source: argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm", Key: "github"}},
+			vault:  fakeSecretReader{"secret/data/scm": {"github": "vault-token\n"}},
+			token:  "vault-token",
+		},
+		{
+			name:   "vault path cleaned",
+			source: argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "/secret/data/scm/apps/../github/", Key: "token"}},
+			vault:  fakeSecretReader{"secret/data/scm/github": {"token": "vault-token"}},
+			token:  "vault-token",
+		},
+		{
+			// The secrets exist, so only the vault path prefix keeps them from being read.
+			name:     "vault path outside of prefix",
+			source:   argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/db", Key: "password"}},
+			vault:    fakeSecretReader{"secret/data/db": {"password": "db-password"}},
+			hasError: true,
+		},
+		{
+			name:     "vault path escaping prefix",
+			source:   argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm/../db", Key: "password"}},
+			vault:    fakeSecretReader{"secret/data/db": {"password": "db-password"}, "secret/data/scm/../db": {"password": "db-password"}},
+			hasError: true,
+		},
+		{
+			name:     "vault path sharing prefix",
+			source:   argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm-admin", Key: "github"}},
+			vault:    fakeSecretReader{"secret/data/scm-admin": {"github": "admin-token"}},
+			hasError: true,
+		},
+		{
+			name:     "vault disabled",
+			source:   argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm", Key: "github"}},
+			hasError: true,
+		},
+		{
+			name:     "vault key not found",
+			source:   argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm", Key: "gitlab"}},
+			vault:    fakeSecretReader{"secret/data/scm": {"github": "vault-token"}},
+			hasError: true,
+		},
+		{
+			name:     "oidc exchange",
+			source:   argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "myorg/myrepo", Identity: "appset"}},
+			exchange: fakeTokenExchanger{},
+			token:    "myorg/myrepo as appset",
+		},
+		{
+			name:     "oidc exchange scope not allowed",
+			source:   argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "otherorg/myrepo", Identity: "appset"}},
+			exchange: fakeTokenExchanger{},
+			hasError: true,
+		},
+		{
+			// Patterns only match within a path segment.
+			name:     "oidc exchange nested scope not allowed",
+			source:   argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "myorg/myrepo/sub", Identity: "appset"}},
+			exchange: fakeTokenExchanger{},
+			hasError: true,
+		},
+		{
+			name:     "oidc exchange identity not allowed",
+			source:   argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "myorg/myrepo", Identity: "admin"}},
+			exchange: fakeTokenExchanger{},
+			hasError: true,
+		},
+		{
+			name:     "oidc exchange disabled",
+			source:   argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "myorg/myrepo", Identity: "appset"}},
+			hasError: true,
+		},
+		{
+			name:     "neither file, env, vault nor oidc exchange",
+			hasError: true,
+		},
+	}
+
+	for _, c := range cases {
+		cc := c
+		t.Run(cc.name, func(t *testing.T) {
+			reader := &TokenReader{Dir: cc.tokenDir, Vault: cc.vault, VaultPathPrefix: "secret/data/scm", OIDCExchange: cc.exchange,
+				OIDCExchangeScopes: []string{"myorg/*"}, OIDCExchangeIdentities: []string{"appset"}}
+			token, err := reader.Read(context.Background(), &cc.source)
+			if cc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, cc.token, token)
+		})
+	}
+}
+
+func TestTokenReaderReadVaultWithoutPrefix(t *testing.T) {
+	reader := &TokenReader{Vault: fakeSecretReader{"secret/data/scm": {"github": "vault-token"}}}
+	_, err := reader.Read(context.Background(), &argoprojiov1alpha1.TokenSource{Vault: &argoprojiov1alpha1.VaultSecretRef{Path: "secret/data/scm", Key: "github"}})
+	assert.EqualError(t, err, `vault secret "secret/data/scm" can't be read as a token: its path must be under the vault path prefix of the controller`)
+}
+
+func TestTokenReaderExchangeWithoutAllowlist(t *testing.T) {
+	reader := &TokenReader{OIDCExchange: fakeTokenExchanger{}}
+	_, err := reader.Read(context.Background(), &argoprojiov1alpha1.TokenSource{OIDCExchange: &argoprojiov1alpha1.OIDCTokenExchange{Scope: "myorg", Identity: "appset"}})
+	assert.EqualError(t, err, `token exchange scope "myorg" is not allowed by the controller`)
+}
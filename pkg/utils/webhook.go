@@ -25,13 +25,21 @@ type WebhookHandler struct {
 	namespace string
 	github    *github.Webhook
 	gitlab    *gitlab.Webhook
+	gitea     *giteaWebhook
+	azure     *azureDevOpsWebhook
 	client    client.Client
 }
 
+// WebhookRepoIndex is the name of the field index of ApplicationSets by the repositories referenced by their Git and
+// Pull Request generators, which the handler uses to find the ApplicationSets a webhook event may affect.
+const WebhookRepoIndex = "webhookRepos"
+
 type gitGeneratorInfo struct {
 	Revision    string
 	TouchedHead bool
 	RepoRegexp  *regexp.Regexp
+	// RepoKey is the key of the repository in WebhookRepoIndex.
+	RepoKey string
 }
 
 type prGeneratorInfo struct {
@@ -42,6 +50,8 @@ type prGeneratorGithubInfo struct {
 	Repo      string
 	Owner     string
 	APIRegexp *regexp.Regexp
+	// RepoKey is the key of the repository in WebhookRepoIndex.
+	RepoKey string
 }
 
 func NewWebhookHandler(namespace string, argocdSettingsMgr *argosettings.SettingsManager, client client.Client) (*WebhookHandler, error) {
@@ -58,11 +68,19 @@ func NewWebhookHandler(namespace string, argocdSettingsMgr *argosettings.Setting
 	if err != nil {
 		return nil, fmt.Errorf("Unable to init GitLab webhook: %v", err)
 	}
+	// Like Argo CD, Gitea webhooks are verified with the Gogs secret.
+	giteaHandler := &giteaWebhook{secret: argocdSettings.WebhookGogsSecret}
+	azureDevOpsHandler := &azureDevOpsWebhook{
+		username: argocdSettings.Secrets[settingsWebhookAzureDevOpsUsernameKey],
+		password: argocdSettings.Secrets[settingsWebhookAzureDevOpsPasswordKey],
+	}
 
 	return &WebhookHandler{
 		namespace: namespace,
 		github:    githubHandler,
 		gitlab:    gitlabHandler,
+		gitea:     giteaHandler,
+		azure:     azureDevOpsHandler,
 		client:    client,
 	}, nil
 }
@@ -74,8 +92,15 @@ func (h *WebhookHandler) HandleEvent(payload interface{}) {
 		return
 	}
 
+	// Only the ApplicationSets referencing the repository of the event are candidates for a refresh.
+	var repoKey string
+	if gitGenInfo != nil {
+		repoKey = gitGenInfo.RepoKey
+	} else {
+		repoKey = prGenInfo.Github.RepoKey
+	}
 	appSetList := &v1alpha1.ApplicationSetList{}
-	err := h.client.List(context.Background(), appSetList, &client.ListOptions{})
+	err := h.client.List(context.Background(), appSetList, client.MatchingFields{WebhookRepoIndex: repoKey})
 	if err != nil {
 		log.Errorf("Failed to list applicationsets: %v", err)
 		return
@@ -83,10 +108,17 @@ func (h *WebhookHandler) HandleEvent(payload interface{}) {
 
 	for _, appSet := range appSetList.Items {
 		shouldRefresh := false
-		for _, gen := range appSet.Spec.Generators {
-			// check if the ApplicationSet uses the git generator that is relevant to the payload
-			shouldRefresh = shouldRefreshGitGenerator(gen.Git, gitGenInfo) || shouldRefreshPRGenerator(gen.PullRequest, prGenInfo)
-			if shouldRefresh {
+		gitGens, prGens := getWebhookGenerators(&appSet)
+		// check if the ApplicationSet uses a generator that is relevant to the payload
+		for _, gen := range gitGens {
+			if shouldRefreshGitGenerator(gen, gitGenInfo) {
+				shouldRefresh = true
+				break
+			}
+		}
+		for _, gen := range prGens {
+			if shouldRefreshPRGenerator(gen, prGenInfo) {
+				shouldRefresh = true
 				break
 			}
 		}
@@ -106,10 +138,16 @@ func (h *WebhookHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	switch {
+	// Gitea needs to be checked before GitHub, since its events also carry GitHub headers.
+	case r.Header.Get("X-Gitea-Event") != "":
+		payload, err = h.gitea.Parse(r)
 	case r.Header.Get("X-GitHub-Event") != "":
 		payload, err = h.github.Parse(r, github.PushEvent, github.PullRequestEvent)
 	case r.Header.Get("X-Gitlab-Event") != "":
 		payload, err = h.gitlab.Parse(r, gitlab.PushEvents, gitlab.TagEvents)
+	// Azure DevOps service hooks carry no event header, but every request has an activity ID.
+	case r.Header.Get("X-Vss-Activityid") != "":
+		payload, err = h.azure.Parse(r)
 	default:
 		log.Debug("Ignoring unknown webhook event")
 		http.Error(w, "Unknown webhook event", http.StatusBadRequest)
@@ -149,6 +187,15 @@ func getGitGeneratorInfo(payload interface{}) *gitGeneratorInfo {
 		webURL = payload.Project.WebURL
 		revision = parseRevision(payload.Ref)
 		touchedHead = payload.Project.DefaultBranch == revision
+	case giteaPushPayload:
+		webURL = payload.Repository.HTMLURL
+		revision = parseRevision(payload.Ref)
+		touchedHead = payload.Repository.DefaultBranch == revision
+	case azureDevOpsPushPayload:
+		webURL = payload.Repository.RemoteURL
+		// Like Argo CD, only the first ref updated by the push is considered.
+		revision = parseRevision(payload.RefUpdates[0].Name)
+		touchedHead = parseRevision(payload.Repository.DefaultBranch) == revision
 	default:
 		return nil
 	}
@@ -167,8 +214,10 @@ func getGitGeneratorInfo(payload interface{}) *gitGeneratorInfo {
 	}
 
 	return &gitGeneratorInfo{
+		Revision:    revision,
 		RepoRegexp:  repoRegexp,
 		TouchedHead: touchedHead,
+		RepoKey:     normalizeRepoURL(webURL),
 	}
 }
 
@@ -196,6 +245,7 @@ func getPRGeneratorInfo(payload interface{}) *prGeneratorInfo {
 			Repo:      payload.Repository.Name,
 			Owner:     payload.Repository.Owner.Login,
 			APIRegexp: apiRegexp,
+			RepoKey:   githubPRRepoKey(urlObj.Hostname(), payload.Repository.Owner.Login, payload.Repository.Name),
 		}
 	default:
 		return nil
@@ -282,6 +332,97 @@ func shouldRefreshPRGenerator(gen *v1alpha1.PullRequestGenerator, info *prGenera
 	return true
 }
 
+// IndexApplicationSetRepos returns the keys of the repositories referenced by the Git and Pull Request generators of
+// an ApplicationSet, including those nested in Matrix and Merge generators, for WebhookRepoIndex.
+func IndexApplicationSetRepos(obj client.Object) []string {
+	appSet, ok := obj.(*v1alpha1.ApplicationSet)
+	if !ok {
+		return nil
+	}
+	gitGens, prGens := getWebhookGenerators(appSet)
+	keys := []string{}
+	for _, gen := range gitGens {
+		if key := normalizeRepoURL(gen.RepoURL); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	for _, gen := range prGens {
+		if gen.Github == nil {
+			continue
+		}
+		api := gen.Github.API
+		if api == "" {
+			api = "https://api.github.com/"
+		}
+		apiURL, err := url.Parse(api)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, githubPRRepoKey(apiURL.Hostname(), gen.Github.Owner, gen.Github.Repo))
+	}
+	return keys
+}
+
+// getWebhookGenerators returns the Git and Pull Request generators of the ApplicationSet, including those nested in
+// Matrix and Merge generators.
+func getWebhookGenerators(appSet *v1alpha1.ApplicationSet) ([]*v1alpha1.GitGenerator, []*v1alpha1.PullRequestGenerator) {
+	var gitGens []*v1alpha1.GitGenerator
+	var prGens []*v1alpha1.PullRequestGenerator
+	var addNested func(generators []v1alpha1.ApplicationSetNestedGenerator)
+	addNested = func(generators []v1alpha1.ApplicationSetNestedGenerator) {
+		for _, gen := range generators {
+			if gen.Git != nil {
+				gitGens = append(gitGens, gen.Git)
+			}
+			if gen.PullRequest != nil {
+				prGens = append(prGens, gen.PullRequest)
+			}
+			// Invalid nested generators are reported by the controller, so they are simply skipped here.
+			if matrix, err := v1alpha1.ToNestedMatrixGenerator(gen.Matrix); err == nil && matrix != nil {
+				addNested(matrix.ToMatrixGenerator().Generators)
+			}
+			if merge, err := v1alpha1.ToNestedMergeGenerator(gen.Merge); err == nil && merge != nil {
+				addNested(merge.ToMergeGenerator().Generators)
+			}
+		}
+	}
+	for _, gen := range appSet.Spec.Generators {
+		if gen.Git != nil {
+			gitGens = append(gitGens, gen.Git)
+		}
+		if gen.PullRequest != nil {
+			prGens = append(prGens, gen.PullRequest)
+		}
+		if gen.Matrix != nil {
+			addNested(gen.Matrix.Generators)
+		}
+		if gen.Merge != nil {
+			addNested(gen.Merge.Generators)
+		}
+	}
+	return gitGens, prGens
+}
+
+// normalizeRepoURL reduces the URL of a Git repository to its lowercased host and path, without user, port and .git
+// suffix, so that the HTTPS and SSH URLs of a repository have the same key in WebhookRepoIndex.
+func normalizeRepoURL(repoURL string) string {
+	if !strings.Contains(repoURL, "://") {
+		// scp-like syntax, eg git@github.com:org/repo.git
+		repoURL = "ssh://" + strings.Replace(repoURL, ":", "/", 1)
+	}
+	urlObj, err := url.Parse(repoURL)
+	if err != nil || urlObj.Hostname() == "" {
+		return ""
+	}
+	path := strings.TrimSuffix(strings.Trim(urlObj.Path, "/"), ".git")
+	return strings.ToLower(urlObj.Hostname() + "/" + path)
+}
+
+// githubPRRepoKey returns the key in WebhookRepoIndex of the GitHub repository targeted by a Pull Request generator.
+func githubPRRepoKey(apiHost, owner, repo string) string {
+	return strings.ToLower("pullrequest:github:" + apiHost + "/" + owner + "/" + repo)
+}
+
 func refreshApplicationSet(c client.Client, appSet *v1alpha1.ApplicationSet) error {
 	// patch the ApplicationSet with the refresh annotation to reconcile
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	// azureDevOpsPushEvent is the event type of Azure DevOps service hooks for pushes to Git repositories.
+	azureDevOpsPushEvent = "git.push"
+
+	// settingsWebhookAzureDevOpsUsernameKey and settingsWebhookAzureDevOpsPasswordKey are the keys of argocd-secret
+	// holding the basic authentication credentials configured for the service hooks.
+	settingsWebhookAzureDevOpsUsernameKey = "webhook.azuredevops.username"
+	settingsWebhookAzureDevOpsPasswordKey = "webhook.azuredevops.password"
+)
+
+var (
+	errAzureDevOpsInvalidHTTPMethod = errors.New("invalid HTTP Method")
+	errAzureDevOpsBasicAuthFailed   = errors.New("basic authentication failed")
+)
+
+// azureDevOpsWebhook parses the service hook events sent by Azure DevOps. Service hooks can't be signed, but can be
+// configured to authenticate with basic authentication.
+type azureDevOpsWebhook struct {
+	username string
+	password string
+}
+
+// azureDevOpsEvent is the envelope of every Azure DevOps service hook event.
+type azureDevOpsEvent struct {
+	EventType string          `json:"eventType"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+// azureDevOpsPushPayload is the subset of the resource of a git.push event used by the handler.
+type azureDevOpsPushPayload struct {
+	RefUpdates []azureDevOpsRefUpdate `json:"refUpdates"`
+	Repository azureDevOpsRepository  `json:"repository"`
+}
+
+type azureDevOpsRefUpdate struct {
+	Name string `json:"name"`
+}
+
+type azureDevOpsRepository struct {
+	RemoteURL     string `json:"remoteUrl"`
+	DefaultBranch string `json:"defaultBranch"`
+}
+
+// Parse authenticates the event, if credentials are configured, and parses its payload.
+func (hook *azureDevOpsWebhook) Parse(r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, errAzureDevOpsInvalidHTTPMethod
+	}
+	if hook.username != "" || hook.password != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(hook.username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(hook.password)) != 1 {
+			return nil, errAzureDevOpsBasicAuthFailed
+		}
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payload: %v", err)
+	}
+
+	var event azureDevOpsEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("error parsing payload: %v", err)
+	}
+	if event.EventType != azureDevOpsPushEvent {
+		return nil, fmt.Errorf("event %q not defined to be parsed", event.EventType)
+	}
+	var payload azureDevOpsPushPayload
+	if err := json.Unmarshal(event.Resource, &payload); err != nil {
+		return nil, fmt.Errorf("error parsing payload: %v", err)
+	}
+	if len(payload.RefUpdates) == 0 {
+		return nil, fmt.Errorf("error parsing payload: push updates no refs")
+	}
+	return payload, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// giteaPushEvent is the value of the X-Gitea-Event header of push events.
+const giteaPushEvent = "push"
+
+var (
+	errGiteaInvalidHTTPMethod      = errors.New("invalid HTTP Method")
+	errGiteaMissingSignatureHeader = errors.New("missing X-Gitea-Signature Header")
+	errGiteaHMACVerificationFailed = errors.New("HMAC verification failed")
+)
+
+// giteaWebhook parses the webhook events sent by Gitea. Gitea events also carry the headers of GitHub and Gogs
+// events, but their payloads differ, so they need a parser of their own.
+type giteaWebhook struct {
+	secret string
+}
+
+// giteaPushPayload is the subset of a Gitea push event used by the handler.
+type giteaPushPayload struct {
+	Ref        string          `json:"ref"`
+	Repository giteaRepository `json:"repository"`
+}
+
+type giteaRepository struct {
+	HTMLURL       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// Parse verifies the signature of the event, if a secret is configured, and parses its payload.
+func (hook *giteaWebhook) Parse(r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, errGiteaInvalidHTTPMethod
+	}
+	event := r.Header.Get("X-Gitea-Event")
+	if event != giteaPushEvent {
+		return nil, fmt.Errorf("event %q not defined to be parsed", event)
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payload: %v", err)
+	}
+
+	if hook.secret != "" {
+		signature := r.Header.Get("X-Gitea-Signature")
+		if signature == "" {
+			return nil, errGiteaMissingSignatureHeader
+		}
+		mac := hmac.New(sha256.New, []byte(hook.secret))
+		_, _ = mac.Write(body)
+		if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+			return nil, errGiteaHMACVerificationFailed
+		}
+	}
+
+	var payload giteaPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("error parsing payload: %v", err)
+	}
+	return payload, nil
+}
@@ -3,6 +3,10 @@ package utils
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -15,11 +19,14 @@ import (
 	argov1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	argosettings "github.com/argoproj/argo-cd/v2/util/settings"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/go-playground/webhooks.v5/github"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -51,6 +58,33 @@ func TestWebhookHandler(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedRefresh:    true,
 		},
+		{
+			desc:               "WebHook from a Gitea repository via Commit",
+			headerKey:          "X-Gitea-Event",
+			headerValue:        "push",
+			payloadFile:        "gitea-push-event.json",
+			effectedAppSets:    []string{"git-gitea"},
+			expectedStatusCode: http.StatusOK,
+			expectedRefresh:    true,
+		},
+		{
+			desc:               "WebHook from a Gitea repository via an unsupported event",
+			headerKey:          "X-Gitea-Event",
+			headerValue:        "issues",
+			payloadFile:        "gitea-push-event.json",
+			effectedAppSets:    []string{"git-gitea"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRefresh:    false,
+		},
+		{
+			desc:               "WebHook from an Azure DevOps repository via Commit",
+			headerKey:          "X-Vss-Activityid",
+			headerValue:        "7be00d2b-1ab0-4f9d-8bd4-6bf1d1b7a0e3",
+			payloadFile:        "azuredevops-push-event.json",
+			effectedAppSets:    []string{"git-azure-devops"},
+			expectedStatusCode: http.StatusOK,
+			expectedRefresh:    true,
+		},
 		{
 			desc:               "WebHook with an unknown event",
 			headerKey:          "X-Random-Event",
@@ -102,10 +136,12 @@ func TestWebhookHandler(t *testing.T) {
 			fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
 				fakeAppWithGitGenerator("git-github", namespace, "https://github.com/org/repo"),
 				fakeAppWithGitGenerator("git-gitlab", namespace, "https://gitlab/group/name"),
+				fakeAppWithGitGenerator("git-gitea", namespace, "git@gitea.example.com:org/repo.git"),
+				fakeAppWithGitGenerator("git-azure-devops", namespace, "https://fabrikam@dev.azure.com/fabrikam/DefaultCollection/_git/Fabrikam-Fiber-Git"),
 				fakeAppWithPullRequestGenerator("pull-request-github", namespace, "Codertocat", "Hello-World"),
 			).Build()
 			set := argosettings.NewSettingsManager(context.TODO(), fakeClient, namespace)
-			h, err := NewWebhookHandler(namespace, set, fc)
+			h, err := NewWebhookHandler(namespace, set, &repoIndexClient{Client: fc})
 			assert.Nil(t, err)
 
 			req := httptest.NewRequest("POST", "/api/webhook", nil)
@@ -137,6 +173,153 @@ func TestWebhookHandler(t *testing.T) {
 	}
 }
 
+func TestGiteaWebhookSignature(t *testing.T) {
+	eventJSON, err := ioutil.ReadFile(filepath.Join("testdata", "gitea-push-event.json"))
+	assert.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write(eventJSON)
+	validSignature := hex.EncodeToString(mac.Sum(nil))
+
+	tt := []struct {
+		desc        string
+		signature   string
+		expectedErr error
+	}{
+		{
+			desc:      "Valid signature",
+			signature: validSignature,
+		},
+		{
+			desc:        "Missing signature",
+			expectedErr: errGiteaMissingSignatureHeader,
+		},
+		{
+			desc:        "Invalid signature",
+			signature:   hex.EncodeToString([]byte("invalid")),
+			expectedErr: errGiteaHMACVerificationFailed,
+		},
+	}
+	for _, test := range tt {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/webhook", bytes.NewReader(eventJSON))
+			req.Header.Set("X-Gitea-Event", "push")
+			if test.signature != "" {
+				req.Header.Set("X-Gitea-Signature", test.signature)
+			}
+			hook := &giteaWebhook{secret: "secret"}
+			payload, err := hook.Parse(req)
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "https://gitea.example.com/org/repo", payload.(giteaPushPayload).Repository.HTMLURL)
+		})
+	}
+}
+
+func TestAzureDevOpsWebhookBasicAuth(t *testing.T) {
+	eventJSON, err := ioutil.ReadFile(filepath.Join("testdata", "azuredevops-push-event.json"))
+	assert.NoError(t, err)
+
+	tt := []struct {
+		desc        string
+		username    string
+		password    string
+		expectedErr error
+	}{
+		{
+			desc:     "Valid credentials",
+			username: "user",
+			password: "secret",
+		},
+		{
+			desc:        "Missing credentials",
+			expectedErr: errAzureDevOpsBasicAuthFailed,
+		},
+		{
+			desc:        "Invalid password",
+			username:    "user",
+			password:    "guess",
+			expectedErr: errAzureDevOpsBasicAuthFailed,
+		},
+	}
+	for _, test := range tt {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/webhook", bytes.NewReader(eventJSON))
+			if test.username != "" {
+				req.SetBasicAuth(test.username, test.password)
+			}
+			hook := &azureDevOpsWebhook{username: "user", password: "secret"}
+			payload, err := hook.Parse(req)
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "refs/heads/main", payload.(azureDevOpsPushPayload).RefUpdates[0].Name)
+		})
+	}
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	for _, repoURL := range []string{
+		"https://github.com/Org/Repo",
+		"https://github.com/org/repo.git",
+		"https://user@github.com:443/org/repo/",
+		"git@github.com:org/repo.git",
+		"ssh://git@github.com/org/repo",
+	} {
+		assert.Equal(t, "github.com/org/repo", normalizeRepoURL(repoURL), repoURL)
+	}
+	assert.Equal(t, "", normalizeRepoURL(""))
+}
+
+func TestIndexApplicationSetRepos(t *testing.T) {
+	appSet := fakeAppWithGitGenerator("git-github", "test", "git@github.com:org/repo.git")
+	appSet.Spec.Generators = append(appSet.Spec.Generators,
+		fakeAppWithPullRequestGenerator("pull-request-github", "test", "Codertocat", "Hello-World").Spec.Generators[0],
+		argoprojiov1alpha1.ApplicationSetGenerator{
+			Matrix: &argoprojiov1alpha1.MatrixGenerator{
+				Generators: []argoprojiov1alpha1.ApplicationSetNestedGenerator{
+					{
+						Git: &argoprojiov1alpha1.GitGenerator{RepoURL: "https://gitlab/group/name"},
+					},
+					{
+						Merge: &apiextensionsv1.JSON{
+							Raw: []byte(`{"generators": [{"git": {"repoURL": "https://gitea.example.com/org/repo.git"}}]}`),
+						},
+					},
+				},
+			},
+		},
+	)
+
+	assert.Equal(t, []string{
+		"github.com/org/repo",
+		"gitlab/group/name",
+		"gitea.example.com/org/repo",
+		"pullrequest:github:api.github.com/codertocat/hello-world",
+	}, IndexApplicationSetRepos(appSet))
+}
+
+func TestGetGitGeneratorInfoRevision(t *testing.T) {
+	eventJSON, err := ioutil.ReadFile(filepath.Join("testdata", "github-commit-event.json"))
+	assert.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/webhook", bytes.NewReader(eventJSON))
+	req.Header.Set("X-GitHub-Event", "push")
+	hook, err := github.New()
+	assert.NoError(t, err)
+	payload, err := hook.Parse(req, github.PushEvent)
+	assert.NoError(t, err)
+
+	info := getGitGeneratorInfo(payload)
+	assert.Equal(t, "master", info.Revision)
+	assert.Equal(t, "github.com/org/repo", info.RepoKey)
+	assert.True(t, shouldRefreshGitGenerator(&v1alpha1.GitGenerator{RepoURL: "https://github.com/org/repo", Revision: "master"}, info))
+	assert.False(t, shouldRefreshGitGenerator(&v1alpha1.GitGenerator{RepoURL: "https://github.com/org/repo", Revision: "dev"}, info))
+}
+
 func TestGenRevisionHasChanged(t *testing.T) {
 	assert.True(t, genRevisionHasChanged(&v1alpha1.GitGenerator{}, "master", true))
 	assert.False(t, genRevisionHasChanged(&v1alpha1.GitGenerator{}, "master", false))
@@ -187,6 +370,40 @@ func fakeAppWithPullRequestGenerator(name, namespace, owner, repo string) *argop
 	}
 }
 
+// repoIndexClient serves ApplicationSet lists selected by WebhookRepoIndex like a cache indexed with
+// IndexApplicationSetRepos would, since the fake client ignores field selectors.
+type repoIndexClient struct {
+	client.Client
+}
+
+func (c *repoIndexClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector == nil {
+		return fmt.Errorf("list without a %s field selector", WebhookRepoIndex)
+	}
+	repoKey, ok := listOpts.FieldSelector.RequiresExactMatch(WebhookRepoIndex)
+	if !ok {
+		return fmt.Errorf("unexpected field selector %q", listOpts.FieldSelector)
+	}
+	listOpts.FieldSelector = nil
+	if err := c.Client.List(ctx, list, listOpts); err != nil {
+		return err
+	}
+	appSetList, ok := list.(*argoprojiov1alpha1.ApplicationSetList)
+	if !ok {
+		return fmt.Errorf("unexpected list type %T", list)
+	}
+	var items []argoprojiov1alpha1.ApplicationSet
+	for i := range appSetList.Items {
+		if ContainsString(IndexApplicationSetRepos(&appSetList.Items[i]), repoKey) {
+			items = append(items, appSetList.Items[i])
+		}
+	}
+	appSetList.Items = items
+	return nil
+}
+
 func newFakeClient(ns string) *kubefake.Clientset {
 	s := runtime.NewScheme()
 	s.AddKnownTypes(argoprojiov1alpha1.GroupVersion, &argoprojiov1alpha1.ApplicationSet{})